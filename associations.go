@@ -0,0 +1,320 @@
+package gormrepository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const withAssociationsContextKey = "__with_associations"
+
+// WithAssociations returns an option making Create, CreateMany and Save persist the associated
+// entities set on the entity, e.g. a user with its profile and posts, instead of omitting
+// them. Associated entities without an Id get one as the entity does; related rows that
+// already exist are left unchanged, as GORM upserts associations with ON CONFLICT DO NOTHING.
+// Hooks, validation and the audit trail only cover the entity itself.
+func WithAssociations() Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(withAssociationsContextKey, true)
+	}
+}
+
+// withAssociations reports whether the call uses WithAssociations
+func withAssociations(db *gorm.DB) bool {
+	value, _ := db.Get(withAssociationsContextKey)
+	return value == true
+}
+
+// omitAssociations excludes the associations from the writes of db, unless the call uses
+// WithAssociations
+func omitAssociations(db *gorm.DB) *gorm.DB {
+	if withAssociations(db) {
+		return db
+	}
+	return db.Omit(clause.Associations)
+}
+
+// prepareAssociations assigns Ids to the associated entities of entity when the call persists
+// them
+func (r *GormRepository[T]) prepareAssociations(db *gorm.DB, entity *T) error {
+	if !withAssociations(db) {
+		return nil
+	}
+	return r.assignNestedIds(db, reflect.ValueOf(entity), make(map[uintptr]bool))
+}
+
+const joinValuesContextKey = "__join_values"
+
+// WithJoinValues returns an option setting columns of the join rows written by AppendAssociation
+// and ReplaceAssociation, for many2many join tables with more columns than the foreign keys:
+//
+//	err := postRepo.AppendAssociation(ctx, post, "Tags", tags,
+//		gr.WithJoinValues(map[string]interface{}{"added_by": actorId, "added_at": time.Now()}))
+//
+// Keys are join table columns. The values are set in the same transaction as the association.
+func WithJoinValues(values map[string]interface{}) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(joinValuesContextKey, values)
+	}
+}
+
+// writeAssociation runs write, an association change, setting the join values of the call on
+// the join rows linking entity to values
+func (r *GormRepository[T]) writeAssociation(db *gorm.DB, entity *T, association string, values interface{}, write func(db *gorm.DB) error) error {
+	joinValues, _ := db.Get(joinValuesContextKey)
+	if columns, _ := joinValues.(map[string]interface{}); len(columns) > 0 {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := write(tx); err != nil {
+				return err
+			}
+			return setJoinValues(tx, entity, association, values, columns)
+		})
+	}
+	return write(db)
+}
+
+// setJoinValues updates columns of the join rows linking entity to values
+func setJoinValues(db *gorm.DB, entity interface{}, association string, values interface{}, columns map[string]interface{}) error {
+	ctx := db.Statement.Context
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil {
+		return err
+	}
+	rel, ok := stmt.Schema.Relationships.Relations[association]
+	if !ok {
+		return fmt.Errorf("%w: %s", gorm.ErrUnsupportedRelation, association)
+	}
+	if rel.JoinTable == nil {
+		return fmt.Errorf("join values need a many2many association, %s isn't", association)
+	}
+
+	var owner, related []clause.Expression
+	var relatedValues []reflect.Value
+	reflectValues := reflect.Indirect(reflect.ValueOf(values))
+	if reflectValues.Kind() == reflect.Slice || reflectValues.Kind() == reflect.Array {
+		for i := 0; i < reflectValues.Len(); i++ {
+			relatedValues = append(relatedValues, reflect.Indirect(reflectValues.Index(i)))
+		}
+	} else {
+		relatedValues = append(relatedValues, reflectValues)
+	}
+	if len(relatedValues) == 0 {
+		return nil
+	}
+
+	relatedConditions := make([][]clause.Expression, len(relatedValues))
+	for _, ref := range rel.References {
+		column := clause.Column{Table: rel.JoinTable.Table, Name: ref.ForeignKey.DBName}
+		switch {
+		case ref.PrimaryKey == nil:
+			owner = append(owner, clause.Eq{Column: column, Value: ref.PrimaryValue})
+		case ref.OwnPrimaryKey:
+			value, _ := ref.PrimaryKey.ValueOf(ctx, reflect.Indirect(reflect.ValueOf(entity)))
+			owner = append(owner, clause.Eq{Column: column, Value: value})
+		default:
+			for i, relatedValue := range relatedValues {
+				value, _ := ref.PrimaryKey.ValueOf(ctx, relatedValue)
+				relatedConditions[i] = append(relatedConditions[i], clause.Eq{Column: column, Value: value})
+			}
+		}
+	}
+	for _, conditions := range relatedConditions {
+		related = append(related, clause.And(conditions...))
+	}
+
+	return db.Session(&gorm.Session{NewDB: true}).
+		Table(rel.JoinTable.Table).
+		Where(clause.And(append(owner, clause.Or(related...))...)).
+		Updates(columns).Error
+}
+
+// FindAssociationPaginated returns a page of the association of entity, e.g. the posts of a
+// user, without loading the whole association. R is the related entity type:
+//
+//	posts, err := gr.FindAssociationPaginated[Post](ctx, userRepo, user, "Posts", 1, 20)
+//
+// Options apply to the related rows, to filter or order them; without an order the rows are
+// ordered by primary key. Being generic over R, it is a function rather than a method of the
+// repository.
+func FindAssociationPaginated[R any, T any](ctx context.Context, repo *GormRepository[T], entity *T, association string, page int, pageSize int, options ...Option) (*PaginationResult[*R], error) {
+	var result *PaginationResult[*R]
+	err := repo.intercept(ctx, "FindAssociationPaginated", true, func(ctx context.Context) (err error) {
+		result, err = findAssociationPaginated[R](ctx, repo, entity, association, page, pageSize, options...)
+		return err
+	})
+	return result, err
+}
+
+func findAssociationPaginated[R any, T any](ctx context.Context, repo *GormRepository[T], entity *T, association string, page int, pageSize int, options ...Option) (*PaginationResult[*R], error) {
+	pageSize, err := repo.pageSize(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	db, cancel := repo.session(ctx, options)
+	defer cancel()
+	db = repo.routeRead(db)
+
+	result := &PaginationResult[*R]{}
+	count := db.Model(entity).Association(association)
+	if result.Total = count.Count(); count.Error != nil {
+		return nil, count.Error
+	}
+
+	offset := (page - 1) * pageSize
+	query := db.Model(entity).Offset(offset).Limit(pageSize)
+	if _, ordered := query.Statement.Clauses["ORDER BY"]; !ordered {
+		// Pages need a stable order, which the database doesn't guarantee without ORDER BY
+		query = orderByRelatedKey(query, entity, association)
+	}
+	if err := query.Association(association).Find(&result.Data); err != nil {
+		return nil, err
+	}
+
+	setPageMetadata(result, page, pageSize, offset)
+	return result, nil
+}
+
+// orderByRelatedKey orders db by the primary key of the rows related to entity by association
+func orderByRelatedKey(db *gorm.DB, entity interface{}, association string) *gorm.DB {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil {
+		return db
+	}
+	rel, ok := stmt.Schema.Relationships.Relations[association]
+	if !ok {
+		return db
+	}
+
+	for _, field := range rel.FieldSchema.PrimaryFields {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Table: rel.FieldSchema.Table, Name: field.DBName}})
+	}
+	return db
+}
+
+// LoadRelation populates relations of an entity already read, as WithRelations would have,
+// e.g. LoadRelation(ctx, user, []string{"Profile", "Posts.Tags"}). The entity is matched by
+// primary key; only its relation fields are set, other fields are left as they are. As other
+// reads, it joins the transaction of WithTx and reads the primary with WithPrimary.
+func (r *GormRepository[T]) LoadRelation(ctx context.Context, entity *T, relations []string, options ...Option) error {
+	return r.intercept(ctx, "LoadRelation", true, func(ctx context.Context) error {
+		return r.loadRelation(ctx, entity, relations, options...)
+	})
+}
+
+func (r *GormRepository[T]) loadRelation(ctx context.Context, entity *T, relations []string, options ...Option) error {
+	if len(relations) == 0 {
+		return nil
+	}
+
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	db = r.routeRead(r.routeTable(ctx, db, entity))
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil {
+		return err
+	}
+	// The entity is read again into a blank entity holding its primary key, used by Take as
+	// condition, so its other fields aren't overwritten
+	loaded := newEntity[T]()
+	target, source := reflect.ValueOf(entity).Elem(), reflect.ValueOf(&loaded).Elem()
+	for _, field := range stmt.Schema.PrimaryFields {
+		value, isZero := field.ValueOf(ctx, target)
+		if isZero {
+			return fmt.Errorf("cannot load relations of an entity without %s", field.Name)
+		}
+		if err := field.Set(ctx, source, value); err != nil {
+			return err
+		}
+	}
+	if err := WithRelations(relations...)(db).Take(&loaded).Error; err != nil {
+		return err
+	}
+
+	for _, relation := range relations {
+		name, _, _ := strings.Cut(relation, ".")
+		rel, ok := stmt.Schema.Relationships.Relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", gorm.ErrUnsupportedRelation, name)
+		}
+		rel.Field.ReflectValueOf(ctx, target).Set(rel.Field.ReflectValueOf(ctx, source))
+	}
+	return nil
+}
+
+// HasAssociation reports whether value is associated to entity, e.g. whether a tag is attached
+// to a post. value is a related entity, or the primary key of one when the related entity has
+// a single primary key. Only the key of the matching row is read, the association isn't loaded.
+func (r *GormRepository[T]) HasAssociation(ctx context.Context, entity *T, association string, value interface{}, options ...Option) (bool, error) {
+	var result bool
+	err := r.intercept(ctx, "HasAssociation", true, func(ctx context.Context) (err error) {
+		result, err = r.hasAssociation(ctx, entity, association, value, options...)
+		return err
+	})
+	return result, err
+}
+
+func (r *GormRepository[T]) hasAssociation(ctx context.Context, entity *T, association string, value interface{}, options ...Option) (bool, error) {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	db = r.routeRead(db)
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil {
+		return false, err
+	}
+	rel, ok := stmt.Schema.Relationships.Relations[association]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", gorm.ErrUnsupportedRelation, association)
+	}
+
+	conditions, err := primaryKeyConditions(ctx, rel.FieldSchema, value)
+	if err != nil {
+		return false, err
+	}
+
+	// The key of one matching row is enough, counting the association isn't needed
+	columns := make([]clause.Column, len(rel.FieldSchema.PrimaryFields))
+	for i, field := range rel.FieldSchema.PrimaryFields {
+		columns[i] = clause.Column{Table: rel.FieldSchema.Table, Name: field.DBName}
+	}
+	found := reflect.New(reflect.SliceOf(reflect.PointerTo(rel.FieldSchema.ModelType)))
+	query := db.Model(entity).Clauses(clause.Select{Columns: columns}).Where(clause.And(conditions...)).Limit(1)
+	if err := query.Association(association).Find(found.Interface()); err != nil {
+		return false, err
+	}
+	return found.Elem().Len() > 0, nil
+}
+
+// primaryKeyConditions returns the conditions matching the primary key of value, an entity of
+// schema or the value of its single primary key
+func primaryKeyConditions(ctx context.Context, related *schema.Schema, value interface{}) ([]clause.Expression, error) {
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+	if !reflectValue.IsValid() {
+		return nil, fmt.Errorf("invalid %s value: nil", related.Name)
+	}
+
+	var conditions []clause.Expression
+	if reflectValue.Type() == related.ModelType {
+		for _, field := range related.PrimaryFields {
+			key, isZero := field.ValueOf(ctx, reflectValue)
+			if isZero {
+				return nil, fmt.Errorf("invalid %s value: zero %s", related.Name, field.Name)
+			}
+			conditions = append(conditions, clause.Eq{Column: clause.Column{Table: related.Table, Name: field.DBName}, Value: key})
+		}
+		return conditions, nil
+	}
+
+	if len(related.PrimaryFields) != 1 {
+		return nil, fmt.Errorf("invalid %s value: %s has a composite primary key", related.Name, related.Name)
+	}
+	field := related.PrimaryFields[0]
+	return []clause.Expression{clause.Eq{Column: clause.Column{Table: related.Table, Name: field.DBName}, Value: value}}, nil
+}
@@ -0,0 +1,279 @@
+package gormrepository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// createUserWithPosts creates a user with count posts titled "Post 1" to "Post <count>"
+func createUserWithPosts(t *testing.T, db *gorm.DB, count int) *tests.TestUser {
+	user := &tests.TestUser{Id: uuid.New(), Name: "Author", Email: uuid.NewString() + "@example.com"}
+	require.NoError(t, db.Omit("Data").Create(user).Error)
+	for i := 1; i <= count; i++ {
+		post := &tests.TestPost{Id: uuid.New(), UserId: user.Id, Title: fmt.Sprintf("Post %d", i)}
+		require.NoError(t, db.Create(post).Error)
+	}
+	return user
+}
+
+func TestFindAssociationPaginated(t *testing.T) {
+	db := setupIntegrationDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	user := createUserWithPosts(t, db, 5)
+	createUserWithPosts(t, db, 3)
+
+	byTitle := WithQuery(func(db *gorm.DB) *gorm.DB {
+		return db.Order("title")
+	})
+	page, err := FindAssociationPaginated[tests.TestPost](ctx, repo, user, "Posts", 2, 2, byTitle)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), page.Total)
+	require.Equal(t, 3, page.LastPage)
+	require.Len(t, page.Data, 2)
+	require.Equal(t, "Post 3", page.Data[0].Title)
+	require.Equal(t, "Post 4", page.Data[1].Title)
+	require.Empty(t, user.Posts, "Expected the entity to be left unchanged")
+
+	page, err = FindAssociationPaginated[tests.TestPost](ctx, repo, user, "Posts", 1, 10, WithQuery(func(db *gorm.DB) *gorm.DB {
+		return db.Where("title <> ?", "Post 1")
+	}))
+	require.NoError(t, err)
+	require.Equal(t, int64(4), page.Total, "Expected options to filter the related rows")
+
+	_, err = FindAssociationPaginated[tests.TestPost](ctx, repo, user, "Missing", 1, 10)
+	require.Error(t, err)
+
+	var ids []string
+	for number := 1; number <= 3; number++ {
+		page, err = FindAssociationPaginated[tests.TestPost](ctx, repo, user, "Posts", number, 2)
+		require.NoError(t, err)
+		for _, post := range page.Data {
+			ids = append(ids, post.Id.String())
+		}
+	}
+	require.Len(t, ids, 5)
+	require.True(t, sort.StringsAreSorted(ids), "Expected pages without order to be ordered by primary key")
+}
+
+func TestGormRepository_ClearAssociation(t *testing.T) {
+	db := setupIntegrationDB(t)
+	postRepo := NewGormRepository[tests.TestPost](db)
+	ctx := context.Background()
+
+	user := createUserWithPosts(t, db, 1)
+	var post tests.TestPost
+	require.NoError(t, db.First(&post, "user_id = ?", user.Id).Error)
+	tags := []*tests.TestTag{{Id: uuid.New(), Name: "go"}, {Id: uuid.New(), Name: "sql"}}
+	require.NoError(t, db.Create(tags).Error)
+	require.NoError(t, postRepo.AppendAssociation(ctx, &post, "Tags", tags))
+
+	require.NoError(t, postRepo.ClearAssociation(ctx, &post, "Tags"))
+
+	found, err := postRepo.FindById(ctx, post.Id, WithRelations("Tags"))
+	require.NoError(t, err)
+	require.Empty(t, found.Tags)
+
+	var count int64
+	require.NoError(t, db.Model(&tests.TestTag{}).Count(&count).Error)
+	require.Equal(t, int64(2), count, "Expected the tags themselves to be kept")
+}
+
+func TestGormRepository_LoadRelation(t *testing.T) {
+	db := setupIntegrationDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	user := createUserWithPosts(t, db, 2)
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: user.Id, Bio: "Gopher"}
+	require.NoError(t, db.Create(profile).Error)
+	var post tests.TestPost
+	require.NoError(t, db.First(&post, "user_id = ?", user.Id).Error)
+	tag := &tests.TestTag{Id: uuid.New(), Name: "go"}
+	require.NoError(t, db.Create(tag).Error)
+	require.NoError(t, db.Model(&post).Association("Tags").Append(tag))
+
+	found, err := repo.FindById(ctx, user.Id)
+	require.NoError(t, err)
+	found.Name = "Renamed"
+
+	require.NoError(t, repo.LoadRelation(ctx, found, []string{"Profile", "Posts.Tags"}))
+	require.Equal(t, "Renamed", found.Name, "Expected other fields to be left as they are")
+	require.NotNil(t, found.Profile)
+	require.Equal(t, "Gopher", found.Profile.Bio)
+	require.Len(t, found.Posts, 2)
+	tags := 0
+	for _, post := range found.Posts {
+		tags += len(post.Tags)
+	}
+	require.Equal(t, 1, tags, "Expected nested relations to be loaded")
+
+	require.Error(t, repo.LoadRelation(ctx, found, []string{"Missing"}))
+	require.Error(t, repo.LoadRelation(ctx, &tests.TestUser{}, []string{"Posts"}), "Expected entities without primary key to fail")
+}
+
+func TestGormRepository_LoadRelation_Routing(t *testing.T) {
+	db := setupIntegrationDB(t)
+	pool := &countingConnPool{ConnPool: db.ConnPool}
+	replica := &gorm.DB{Config: &gorm.Config{ConnPool: pool}}
+	repo := NewGormRepository[tests.TestUser](db, WithReplicas(replica))
+	ctx := context.Background()
+	user := createUserWithPosts(t, db, 1)
+
+	require.NoError(t, repo.LoadRelation(ctx, &tests.TestUser{Id: user.Id}, []string{"Posts"}))
+	replicaQueries := pool.queries.Load()
+	require.NotZero(t, replicaQueries, "Expected relations to be loaded from the replica")
+
+	loaded := &tests.TestUser{Id: user.Id}
+	require.NoError(t, repo.LoadRelation(ctx, loaded, []string{"Posts"}, WithPrimary()))
+	require.Len(t, loaded.Posts, 1)
+
+	tx := repo.BeginTransaction()
+	post := &tests.TestPost{Id: uuid.New(), UserId: user.Id, Title: "Draft"}
+	require.NoError(t, NewGormRepository[tests.TestPost](db).Create(ctx, post, WithTx(tx)))
+	loaded = &tests.TestUser{Id: user.Id}
+	require.NoError(t, repo.LoadRelation(ctx, loaded, []string{"Posts"}, WithTx(tx)))
+	require.Len(t, loaded.Posts, 2, "Expected the post written in the transaction to be loaded")
+	require.NoError(t, tx.Rollback())
+
+	require.Equal(t, replicaQueries, pool.queries.Load(), "Expected WithPrimary and WithTx to stay on the primary")
+}
+
+func TestGormRepository_HasAssociation(t *testing.T) {
+	db := setupIntegrationDB(t)
+	postRepo := NewGormRepository[tests.TestPost](db)
+	userRepo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	user := createUserWithPosts(t, db, 1)
+	other := createUserWithPosts(t, db, 1)
+	var post tests.TestPost
+	require.NoError(t, db.First(&post, "user_id = ?", user.Id).Error)
+	attached := &tests.TestTag{Id: uuid.New(), Name: "go"}
+	detached := &tests.TestTag{Id: uuid.New(), Name: "sql"}
+	require.NoError(t, db.Create([]*tests.TestTag{attached, detached}).Error)
+	require.NoError(t, postRepo.AppendAssociation(ctx, &post, "Tags", []*tests.TestTag{attached}))
+
+	has, err := postRepo.HasAssociation(ctx, &post, "Tags", attached)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	has, err = postRepo.HasAssociation(ctx, &post, "Tags", *detached)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	has, err = postRepo.HasAssociation(ctx, &post, "Tags", attached.Id)
+	require.NoError(t, err)
+	require.True(t, has, "Expected primary keys to be accepted")
+
+	has, err = userRepo.HasAssociation(ctx, user, "Posts", post.Id)
+	require.NoError(t, err)
+	require.True(t, has)
+	has, err = userRepo.HasAssociation(ctx, other, "Posts", post.Id)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	_, err = postRepo.HasAssociation(ctx, &post, "Missing", attached)
+	require.Error(t, err)
+	_, err = postRepo.HasAssociation(ctx, &post, "Tags", &tests.TestTag{})
+	require.Error(t, err, "Expected entities without primary key to fail")
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	recordedRepo := NewGormRepository[tests.TestPost](db.Session(&gorm.Session{Logger: recorder}))
+	has, err = recordedRepo.HasAssociation(ctx, &post, "Tags", attached)
+	require.NoError(t, err)
+	require.True(t, has)
+	require.Len(t, recorder.statements, 1)
+	require.NotContains(t, recorder.statements[0], "count(", "Expected the association not to be counted")
+	require.Regexp(t, "^SELECT .test_tags.\\..id. FROM", recorder.statements[0], "Expected only the key to be read")
+	require.Contains(t, recorder.statements[0], "LIMIT 1")
+}
+
+func TestGormRepository_Create_WithAssociations(t *testing.T) {
+	db := setupIntegrationDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	tag := &tests.TestTag{Name: "go"}
+	user := &tests.TestUser{
+		Name:    "Aggregate",
+		Email:   "aggregate@example.com",
+		Data:    &tests.UserData{},
+		Profile: &tests.TestProfile{Bio: "Gopher"},
+		Posts: []*tests.TestPost{
+			{Title: "First", Tags: []*tests.TestTag{tag}},
+			{Title: "Second", Tags: []*tests.TestTag{tag}},
+		},
+	}
+	require.NoError(t, repo.Create(ctx, user, WithAssociations()))
+	require.NotEqual(t, uuid.Nil, user.Profile.Id, "Expected associated entities to get an Id")
+	require.NotEqual(t, user.Posts[0].Id, user.Posts[1].Id)
+	require.NotEqual(t, uuid.Nil, tag.Id)
+
+	found, err := repo.FindById(ctx, user.Id, WithRelations("Profile", "Posts.Tags"))
+	require.NoError(t, err)
+	require.Equal(t, "Gopher", found.Profile.Bio)
+	require.Len(t, found.Posts, 2)
+	for _, post := range found.Posts {
+		require.Len(t, post.Tags, 1)
+	}
+
+	plain := &tests.TestUser{
+		Name:  "Plain",
+		Email: "plain@example.com",
+		Data:  &tests.UserData{},
+		Posts: []*tests.TestPost{{Id: uuid.New(), Title: "Omitted"}},
+	}
+	require.NoError(t, repo.Create(ctx, plain))
+	var count int64
+	require.NoError(t, db.Model(&tests.TestPost{}).Where("user_id = ?", plain.Id).Count(&count).Error)
+	require.Zero(t, count, "Expected associations to be omitted by default")
+}
+
+func TestGormRepository_AppendAssociation_WithJoinValues(t *testing.T) {
+	db := setupIntegrationDB(t)
+	require.NoError(t, db.Exec("ALTER TABLE post_tags ADD COLUMN added_by TEXT").Error)
+	postRepo := NewGormRepository[tests.TestPost](db)
+	ctx := context.Background()
+
+	user := createUserWithPosts(t, db, 2)
+	var posts []*tests.TestPost
+	require.NoError(t, db.Order("title").Find(&posts, "user_id = ?", user.Id).Error)
+	tags := []*tests.TestTag{{Id: uuid.New(), Name: "go"}, {Id: uuid.New(), Name: "sql"}, {Id: uuid.New(), Name: "orm"}}
+	require.NoError(t, db.Create(tags).Error)
+
+	addedBy := func(post *tests.TestPost, tag *tests.TestTag) string {
+		var value *string
+		require.NoError(t, db.Raw("SELECT added_by FROM post_tags WHERE test_post_id = ? AND test_tag_id = ?", post.Id, tag.Id).Scan(&value).Error)
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	require.NoError(t, postRepo.AppendAssociation(ctx, posts[0], "Tags", tags[:2], WithJoinValues(map[string]interface{}{"added_by": "alice"})))
+	require.NoError(t, postRepo.AppendAssociation(ctx, posts[1], "Tags", tags[0]))
+	require.Equal(t, "alice", addedBy(posts[0], tags[0]))
+	require.Equal(t, "alice", addedBy(posts[0], tags[1]))
+	require.Empty(t, addedBy(posts[1], tags[0]), "Expected the join rows of other entities to be left unchanged")
+
+	require.NoError(t, postRepo.ReplaceAssociation(ctx, posts[0], "Tags", []*tests.TestTag{tags[1], tags[2]}, WithJoinValues(map[string]interface{}{"added_by": "bob"})))
+	require.Equal(t, "bob", addedBy(posts[0], tags[1]))
+	require.Equal(t, "bob", addedBy(posts[0], tags[2]))
+	has, err := postRepo.HasAssociation(ctx, posts[0], "Tags", tags[0])
+	require.NoError(t, err)
+	require.False(t, has)
+
+	userRepo := NewGormRepository[tests.TestUser](db)
+	err = userRepo.AppendAssociation(ctx, user, "Posts", posts[0], WithJoinValues(map[string]interface{}{"added_by": "bob"}))
+	require.Error(t, err, "Expected join values to need a many2many association")
+}
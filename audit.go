@@ -0,0 +1,205 @@
+package gormrepository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ikateclab/gorm-repository/utils"
+)
+
+// Audit operations recorded in AuditEntry.Operation
+const (
+	AuditOperationCreate = "create"
+	AuditOperationUpdate = "update"
+	AuditOperationSave   = "save"
+	AuditOperationDelete = "delete"
+)
+
+// AuditEntry is a single change of an entity persisted by an AuditRecorder.
+// OldValues and NewValues hold the changed fields only, keyed like the entity diff
+// (flattened JSONB paths included). OldValues is nil when the previous state wasn't
+// loaded in the same transaction, since it can't be known without an extra read.
+// Deleted map keys are null in NewValues, as a key set to null, and are listed in
+// RemovedPaths to tell them apart when the diffs are replayed. Fields set by SQL expressions,
+// such as gorm.Expr("views + 1"), are listed in ExpressionPaths and left out of NewValues, as
+// their values aren't known without reading the row.
+type AuditEntry struct {
+	Id              uuid.UUID              `gorm:"type:text;primary_key" json:"id"`
+	EntityType      string                 `gorm:"not null;index:idx_audit_entity" json:"entityType"`
+	EntityId        string                 `gorm:"not null;index:idx_audit_entity" json:"entityId"`
+	Operation       string                 `gorm:"not null" json:"operation"`
+	ActorId         *uuid.UUID             `gorm:"type:text" json:"actorId,omitempty"`
+	OldValues       map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"oldValues,omitempty"`
+	NewValues       map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"newValues,omitempty"`
+	RemovedPaths    []string               `gorm:"type:jsonb;serializer:json" json:"removedPaths,omitempty"`
+	ExpressionPaths []string               `gorm:"type:jsonb;serializer:json" json:"expressionPaths,omitempty"`
+	CreatedAt       time.Time              `json:"createdAt"`
+}
+
+// AuditRecorder persists audit entries. db is bound to the transaction of the audited
+// write, so recorders writing to the database commit or roll back together with it.
+type AuditRecorder interface {
+	Record(ctx context.Context, db *gorm.DB, entry *AuditEntry) error
+}
+
+// GormAuditRecorder stores audit entries in the AuditEntry table
+type GormAuditRecorder struct{}
+
+// Record inserts the entry using the transaction of the audited write
+func (GormAuditRecorder) Record(ctx context.Context, db *gorm.DB, entry *AuditEntry) error {
+	return db.Session(&gorm.Session{NewDB: true}).WithContext(ctx).Create(entry).Error
+}
+
+// ActorExtractor returns the id of the user performing the operation, or uuid.Nil when unknown
+type ActorExtractor func(ctx context.Context) uuid.UUID
+
+// WithAuditRecorder enables the audit trail: UpdateById, UpdateByIdInPlace, UpdateInPlace and
+// Save record their diff with the recorder, in the same transaction as the write.
+// Create records every field of the new entity, the starting point to replay the diffs,
+// and DeleteById records the deletion without values.
+func WithAuditRecorder(recorder AuditRecorder) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.auditRecorder = recorder
+	}
+}
+
+// WithActorExtractor sets how the acting user is read from the context of an operation
+func WithActorExtractor(extractor ActorExtractor) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.actorExtractor = extractor
+	}
+}
+
+// auditEntry builds the audit entry of a tracked change, values being its flattened diff
+func (r *GormRepository[T]) auditEntry(ctx context.Context, c *change[T], values map[string]interface{}) (*AuditEntry, error) {
+	var oldValues map[string]interface{}
+	if c.old != nil {
+		if diffable, ok := any(c.old).(Diffable[T]); ok {
+			var err error
+			if oldValues, err = auditValues(diffable.Diff(c.entity)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	flat, expressionPaths, err := flattenAuditDiff(c.diff)
+	if err != nil {
+		return nil, err
+	}
+
+	// Version 7 ids order the entries recorded at the same time
+	entryId, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &AuditEntry{
+		Id:              entryId,
+		EntityType:      entityTypeName[T](),
+		EntityId:        c.entityId,
+		Operation:       c.operation,
+		OldValues:       oldValues,
+		NewValues:       values,
+		RemovedPaths:    removedPaths(flat),
+		ExpressionPaths: expressionPaths,
+		CreatedAt:       time.Now(),
+	}
+
+	if r.config.actorExtractor != nil {
+		if actorId := r.config.actorExtractor(ctx); actorId != uuid.Nil {
+			entry.ActorId = &actorId
+		}
+	}
+
+	return entry, nil
+}
+
+// entityTypeName returns the name recorded as the entity type of T
+func entityTypeName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().Name()
+}
+
+// primaryKeyString returns the primary key value of entity formatted as a string
+func primaryKeyString(db *gorm.DB, entity interface{}) string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return ""
+	}
+
+	value, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(context.Background(), reflect.ValueOf(entity))
+	return fmt.Sprint(value)
+}
+
+// auditValues converts a diff into plain JSON values: merge expressions are flattened
+// into paths and removed map keys are stored as null
+func auditValues(diff map[string]interface{}) (map[string]interface{}, error) {
+	flat, _, err := flattenAuditDiff(diff)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range flat {
+		if value == utils.Removed {
+			flat[key] = nil
+		}
+	}
+
+	return flat, nil
+}
+
+// flattenAuditDiff flattens diff as utils.FlattenDiff does, leaving out the fields set by SQL
+// expressions other than JSONB merges, returned in order: their values are only known to the
+// database, and the write shouldn't fail for being audited
+func flattenAuditDiff(diff map[string]interface{}) (map[string]interface{}, []string, error) {
+	values := make(map[string]interface{}, len(diff))
+	var expressionPaths []string
+	for key, value := range diff {
+		if _, isExpression := value.(clause.Expression); isExpression {
+			if _, err := utils.FlattenDiff(map[string]interface{}{key: value}); err != nil {
+				expressionPaths = append(expressionPaths, key)
+				continue
+			}
+		}
+		values[key] = value
+	}
+	sort.Strings(expressionPaths)
+
+	flat, err := utils.FlattenDiff(values)
+	return flat, expressionPaths, err
+}
+
+// removedPaths returns the paths of a flattened diff set to utils.Removed, in order
+func removedPaths(flat map[string]interface{}) []string {
+	var paths []string
+	for key, value := range flat {
+		if value == utils.Removed {
+			paths = append(paths, key)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// entityValues converts an entity into its JSON fields
+func entityValues(entity interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
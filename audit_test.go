@@ -0,0 +1,166 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type actorContextKey struct{}
+
+func actorFromContext(ctx context.Context) uuid.UUID {
+	actorId, _ := ctx.Value(actorContextKey{}).(uuid.UUID)
+	return actorId
+}
+
+func createAuditedProfile(t *testing.T, repo *GormRepository[tests.TestProfile]) *tests.TestProfile {
+	profile := &tests.TestProfile{
+		Id:      uuid.New(),
+		UserId:  uuid.New(),
+		Bio:     "Original bio",
+		Website: "https://example.com",
+	}
+	require.NoError(t, repo.Create(context.Background(), profile), "Failed to create test profile")
+	return profile
+}
+
+func findAuditEntries(t *testing.T, db *gorm.DB, entityId uuid.UUID, operation string) []AuditEntry {
+	var entries []AuditEntry
+	err := db.Where(&AuditEntry{EntityId: entityId.String(), Operation: operation}).Order(clause.OrderByColumn{Column: auditColumn(db, "CreatedAt")}).Find(&entries).Error
+	require.NoError(t, err, "Failed to load audit entries")
+	return entries
+}
+
+func TestGormRepository_Audit_UpdateByIdInPlace(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db, WithAuditRecorder(GormAuditRecorder{}), WithActorExtractor(actorFromContext))
+	actorId := uuid.New()
+	ctx := context.WithValue(context.Background(), actorContextKey{}, actorId)
+
+	profile := createAuditedProfile(t, repo)
+
+	err := repo.UpdateByIdInPlace(ctx, profile.Id, profile, func() {
+		profile.Bio = "Audited bio"
+	})
+	require.NoError(t, err, "UpdateByIdInPlace should not fail")
+
+	entries := findAuditEntries(t, db, profile.Id, AuditOperationUpdate)
+	require.Len(t, entries, 1, "Expected one audit entry")
+
+	entry := entries[0]
+	require.Equal(t, "TestProfile", entry.EntityType)
+	require.Equal(t, AuditOperationUpdate, entry.Operation)
+	require.NotNil(t, entry.ActorId, "Expected actor to be recorded")
+	require.Equal(t, actorId, *entry.ActorId)
+	require.Equal(t, map[string]interface{}{"bio": "Audited bio"}, entry.NewValues)
+	require.Equal(t, map[string]interface{}{"bio": "Original bio"}, entry.OldValues)
+}
+
+func TestGormRepository_Audit_UpdateByIdWithoutSnapshot(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithAuditRecorder(GormAuditRecorder{}))
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "original"}
+	require.NoError(t, repo.Create(ctx, entity), "Failed to create test entity")
+
+	entity.Value = "blind update"
+	require.NoError(t, repo.UpdateById(ctx, entity.Id, entity), "UpdateById should not fail")
+
+	entries := findAuditEntries(t, db, entity.Id, AuditOperationUpdate)
+	require.Len(t, entries, 1, "Expected one audit entry")
+	require.Nil(t, entries[0].ActorId, "Expected no actor without an extractor")
+	require.Nil(t, entries[0].OldValues, "Expected unknown old values without a snapshot")
+	require.Equal(t, "blind update", entries[0].NewValues["value"])
+}
+
+func TestGormRepository_Audit_RolledBackWithTransaction(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db, WithAuditRecorder(GormAuditRecorder{}))
+	ctx := context.Background()
+
+	profile := createAuditedProfile(t, repo)
+
+	tx := repo.BeginTransaction()
+	found, err := repo.FindById(ctx, profile.Id, WithTx(tx))
+	require.NoError(t, err, "FindById should not fail")
+
+	err = repo.UpdateInPlace(ctx, found, func() {
+		found.Website = "https://rolled-back.example.com"
+	}, WithTx(tx))
+	require.NoError(t, err, "UpdateInPlace should not fail")
+	require.NoError(t, tx.Rollback(), "Rollback should not fail")
+
+	require.Empty(t, findAuditEntries(t, db, profile.Id, AuditOperationUpdate), "Expected audit entry to be rolled back with the update")
+}
+
+func TestGormRepository_Audit_Save(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db, WithAuditRecorder(GormAuditRecorder{}))
+	ctx := context.Background()
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Bio: "Saved bio"}
+	require.NoError(t, repo.Save(ctx, profile), "Save should not fail")
+
+	entries := findAuditEntries(t, db, profile.Id, AuditOperationSave)
+	require.Len(t, entries, 1, "Expected one audit entry")
+	require.Equal(t, AuditOperationSave, entries[0].Operation)
+	require.Equal(t, "Saved bio", entries[0].NewValues["bio"])
+}
+
+func TestGormRepository_Audit_Disabled(t *testing.T) {
+	db := setupTestDB(t)
+	repo := &GormRepository[tests.TestSimpleEntity]{DB: db}
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "original"}
+	require.NoError(t, repo.Create(ctx, entity), "Failed to create test entity")
+
+	entity.Value = "not audited"
+	require.NoError(t, repo.UpdateById(ctx, entity.Id, entity), "UpdateById should not fail")
+
+	require.Empty(t, findAuditEntries(t, db, entity.Id, AuditOperationUpdate), "Expected no audit entries without a recorder")
+}
+
+// editedProfile is a TestProfile whose Diff appends to the bio with a SQL expression
+type editedProfile struct {
+	Id      uuid.UUID `gorm:"type:text;primary_key" json:"id"`
+	Bio     string    `json:"bio"`
+	Website string    `json:"website"`
+}
+
+func (editedProfile) TableName() string {
+	return "test_profiles"
+}
+
+func (p *editedProfile) Clone() *editedProfile {
+	clone := *p
+	return &clone
+}
+
+func (p *editedProfile) Diff(old *editedProfile) map[string]interface{} {
+	return map[string]interface{}{"bio": gorm.Expr("bio || ?", " (edited)"), "website": p.Website}
+}
+
+func TestGormRepository_Audit_SQLExpression(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[editedProfile](db, WithAuditRecorder(GormAuditRecorder{}))
+	ctx := context.Background()
+	profile := createAuditedProfile(t, NewGormRepository[tests.TestProfile](db))
+
+	err := repo.UpdateById(ctx, profile.Id, &editedProfile{Id: profile.Id, Website: "https://expr.example.com"})
+	require.NoError(t, err, "Expected writes with SQL expressions to be audited")
+	found, err := repo.FindById(ctx, profile.Id)
+	require.NoError(t, err)
+	require.Equal(t, "Original bio (edited)", found.Bio)
+
+	entries := findAuditEntries(t, db, profile.Id, AuditOperationUpdate)
+	require.Len(t, entries, 1)
+	require.Equal(t, map[string]interface{}{"website": "https://expr.example.com"}, entries[0].NewValues)
+	require.Equal(t, []string{"bio"}, entries[0].ExpressionPaths, "Expected the field set by an expression to be listed")
+}
@@ -0,0 +1,113 @@
+package gormrepository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Blame fields populated with the actor returned by the ActorExtractor
+const (
+	createdByIdField = "CreatedById"
+	updatedByIdField = "UpdatedById"
+)
+
+// actorId returns the actor of the operation, false when it isn't known
+func (r *GormRepository[T]) actorId(ctx context.Context) (uuid.UUID, bool) {
+	if r.config.actorExtractor == nil {
+		return uuid.Nil, false
+	}
+	actorId := r.config.actorExtractor(ctx)
+	return actorId, actorId != uuid.Nil
+}
+
+// stampActor sets the blame fields of entity for the given hook event. Creations set
+// CreatedById and UpdatedById, updates UpdatedById (and CreatedById when it isn't set,
+// since Save may insert). Entities without these fields are left untouched.
+func (r *GormRepository[T]) stampActor(ctx context.Context, event HookEvent, entity *T) {
+	actorId, ok := r.actorId(ctx)
+	if !ok {
+		return
+	}
+
+	switch event {
+	case HookBeforeCreate:
+		setUUIDField(entity, createdByIdField, actorId, false)
+		setUUIDField(entity, updatedByIdField, actorId, false)
+	case HookBeforeUpdate:
+		setUUIDField(entity, createdByIdField, actorId, true)
+		setUUIDField(entity, updatedByIdField, actorId, false)
+	}
+}
+
+// stampActorValues returns values with UpdatedById set to the actor, for updates that
+// don't go through an entity. values itself is never modified.
+func (r *GormRepository[T]) stampActorValues(ctx context.Context, db *gorm.DB, values map[string]interface{}) map[string]interface{} {
+	actorId, ok := r.actorId(ctx)
+	if !ok {
+		return values
+	}
+	return withFieldValue[T](db, values, updatedByIdField, actorId)
+}
+
+// setUUIDField sets the uuid.UUID or *uuid.UUID field name of entity to id.
+// With onlyIfZero, fields that are already set are kept.
+func setUUIDField(entity interface{}, name string, id uuid.UUID, onlyIfZero bool) {
+	value := reflect.ValueOf(entity)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	field := value.FieldByName(name)
+	if !field.IsValid() || !field.CanSet() {
+		return
+	}
+
+	idType := reflect.TypeOf(id)
+	switch {
+	case field.Type() == idType:
+		if !onlyIfZero || field.IsZero() {
+			field.Set(reflect.ValueOf(id))
+		}
+	case field.Kind() == reflect.Ptr && field.Type().Elem() == idType:
+		if !onlyIfZero || field.IsNil() || field.Elem().IsZero() {
+			field.Set(reflect.ValueOf(&id))
+		}
+	}
+}
+
+// withFieldValue returns a copy of the update values with the entity field set to value,
+// unless T has no such field or values already update its column
+func withFieldValue[T any](db *gorm.DB, values map[string]interface{}, fieldName string, value interface{}) map[string]interface{} {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return values
+	}
+
+	field := stmt.Schema.LookUpField(fieldName)
+	if field == nil {
+		return values
+	}
+
+	for key := range values {
+		if existing := stmt.Schema.LookUpField(key); existing == field {
+			return values
+		}
+	}
+
+	stamped := make(map[string]interface{}, len(values)+1)
+	for key, existing := range values {
+		stamped[key] = existing
+	}
+	stamped[field.Name] = value
+
+	return stamped
+}
@@ -0,0 +1,57 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type testBlameEntity struct {
+	Id          uuid.UUID  `gorm:"type:text;primary_key" json:"id"`
+	Name        string     `json:"name"`
+	CreatedById uuid.UUID  `gorm:"type:text" json:"createdById"`
+	UpdatedById *uuid.UUID `gorm:"type:text" json:"updatedById"`
+}
+
+func TestGormRepository_ActorBlameFields(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&testBlameEntity{}), "Failed to migrate blame entity")
+
+	repo := NewGormRepository[testBlameEntity](db, WithActorExtractor(actorFromContext))
+	creator, updater := uuid.New(), uuid.New()
+
+	entity := &testBlameEntity{Id: uuid.New(), Name: "created"}
+	createCtx := context.WithValue(context.Background(), actorContextKey{}, creator)
+	require.NoError(t, repo.Create(createCtx, entity), "Create should not fail")
+	require.Equal(t, creator, entity.CreatedById)
+	require.Equal(t, creator, *entity.UpdatedById)
+
+	updateCtx := context.WithValue(context.Background(), actorContextKey{}, updater)
+	updated, err := repo.UpdateByIdWithMap(updateCtx, entity.Id, map[string]interface{}{"name": "updated"})
+	require.NoError(t, err, "UpdateByIdWithMap should not fail")
+
+	found, err := repo.FindById(context.Background(), entity.Id)
+	require.NoError(t, err, "FindById should not fail")
+	require.Equal(t, "updated", found.Name)
+	require.Equal(t, creator, found.CreatedById, "Expected creator to be kept")
+	require.NotNil(t, found.UpdatedById)
+	require.Equal(t, updater, *found.UpdatedById, "Expected map updates to set the updater")
+	require.Equal(t, updater, *updated.UpdatedById)
+
+	require.NoError(t, repo.Save(updateCtx, found), "Save should not fail")
+	require.Equal(t, creator, found.CreatedById, "Expected Save to keep the creator")
+}
+
+func TestGormRepository_ActorBlameFields_UnknownActor(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&testBlameEntity{}), "Failed to migrate blame entity")
+
+	repo := NewGormRepository[testBlameEntity](db, WithActorExtractor(actorFromContext))
+
+	entity := &testBlameEntity{Id: uuid.New(), Name: "anonymous"}
+	require.NoError(t, repo.Create(context.Background(), entity), "Create should not fail")
+	require.Equal(t, uuid.Nil, entity.CreatedById)
+	require.Nil(t, entity.UpdatedById)
+}
@@ -0,0 +1,190 @@
+package gormrepository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// change is a write tracked by the audit trail and the change publisher
+type change[T any] struct {
+	operation string
+	entityId  string
+	entity    *T
+	// old is the snapshot diff was computed against, nil when the previous state isn't known
+	old  *T
+	diff map[string]interface{}
+}
+
+// tracksChanges reports whether writes have to be recorded or published
+func (r *GormRepository[T]) tracksChanges() bool {
+	return r.config.auditRecorder != nil || r.config.changePublisher != nil
+}
+
+// trackChange describes a write for the audit trail and change publisher,
+// or returns nil when neither is enabled
+func (r *GormRepository[T]) trackChange(operation string, entityId string, entity *T, old *T, diff map[string]interface{}) *change[T] {
+	if !r.tracksChanges() {
+		return nil
+	}
+
+	return &change[T]{
+		operation: operation,
+		entityId:  entityId,
+		entity:    entity,
+		old:       old,
+		diff:      diff,
+	}
+}
+
+// createChange describes a Create, holding all fields of the entity
+func (r *GormRepository[T]) createChange(db *gorm.DB, entity *T) (*change[T], error) {
+	if !r.tracksChanges() {
+		return nil, nil
+	}
+
+	values, err := entityValues(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.trackChange(AuditOperationCreate, primaryKeyString(db, entity), entity, nil, values), nil
+}
+
+// saveChange describes a Save. Diffable entities hold their changes against the transaction
+// snapshot (or every set field without one), other entities all their fields.
+func (r *GormRepository[T]) saveChange(db *gorm.DB, entity *T) (*change[T], error) {
+	if !r.tracksChanges() {
+		return nil, nil
+	}
+
+	entityId := primaryKeyString(db, entity)
+
+	diffable, ok := any(entity).(Diffable[T])
+	if !ok {
+		values, err := entityValues(entity)
+		if err != nil {
+			return nil, err
+		}
+		return r.trackChange(AuditOperationSave, entityId, entity, nil, values), nil
+	}
+
+	clone, isSnapshot := getCloneForDiff(db, entity)
+	if !isSnapshot {
+		return r.trackChange(AuditOperationSave, entityId, entity, nil, diffable.Diff(clone)), nil
+	}
+	return r.trackChange(AuditOperationSave, entityId, entity, clone, diffable.Diff(clone)), nil
+}
+
+// writeTracked runs write, records the audit entry of c in the same transaction and publishes
+// c once that transaction commits. Writes already running within WithTx use that transaction,
+// other writes are wrapped in a new one when they are audited.
+func (r *GormRepository[T]) writeTracked(ctx context.Context, db *gorm.DB, c *change[T], write func(db *gorm.DB) error) error {
+	if c == nil {
+		return write(db)
+	}
+	return r.writeTrackedBatch(ctx, db, []*change[T]{c}, write)
+}
+
+// writeTrackedBatch is writeTracked for a write changing several entities at once
+func (r *GormRepository[T]) writeTrackedBatch(ctx context.Context, db *gorm.DB, changes []*change[T], write func(db *gorm.DB) error) error {
+	if len(changes) == 0 {
+		return write(db)
+	}
+
+	values := make([]map[string]interface{}, len(changes))
+	var entries []*AuditEntry
+	for i, c := range changes {
+		var err error
+		if values[i], err = auditValues(c.diff); err != nil {
+			return err
+		}
+
+		if r.config.auditRecorder != nil {
+			entry, err := r.auditEntry(ctx, c, values[i])
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	record := func(db *gorm.DB) error {
+		if err := write(db); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := r.config.auditRecorder.Record(ctx, db, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	events := func() []ChangeEvent {
+		events := make([]ChangeEvent, len(changes))
+		for i, c := range changes {
+			events[i] = r.changeEvent(c, values[i])
+		}
+		return events
+	}
+
+	if txInterface, inTx := db.Get(txContextKey); inTx {
+		if err := record(db); err != nil {
+			return err
+		}
+		if tx, ok := txInterface.(*Tx); ok && r.config.changePublisher != nil {
+			committed := events()
+			tx.OnCommit(func() { r.publish(ctx, committed...) })
+		}
+		return nil
+	}
+
+	var err error
+	if len(entries) == 0 {
+		err = record(db)
+	} else {
+		err = db.Transaction(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.config.changePublisher != nil {
+		r.publish(ctx, events()...)
+	}
+	return nil
+}
+
+// changeEvent builds the event published for c. The entity is cloned when possible, so
+// events published at commit time carry the state of the write.
+func (r *GormRepository[T]) changeEvent(c *change[T], values map[string]interface{}) ChangeEvent {
+	event := ChangeEvent{
+		EntityType: entityTypeName[T](),
+		EntityId:   c.entityId,
+		Operation:  c.operation,
+		Diff:       values,
+		OccurredAt: time.Now(),
+	}
+
+	if c.entity != nil {
+		if diffable, ok := any(c.entity).(Diffable[T]); ok {
+			event.Entity = diffable.Clone()
+		} else {
+			event.Entity = c.entity
+		}
+	}
+
+	return event
+}
+
+// publish sends events to the configured change publisher, if any
+func (r *GormRepository[T]) publish(ctx context.Context, events ...ChangeEvent) {
+	if r.config.changePublisher == nil {
+		return
+	}
+	for _, event := range events {
+		r.config.changePublisher.Publish(ctx, event)
+	}
+}
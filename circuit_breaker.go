@@ -0,0 +1,197 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrCircuitOpen is returned without reaching the database while a CircuitBreaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every operation through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every operation with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probes through to test the database
+	CircuitHalfOpen
+)
+
+// CircuitBreakerConfig configures a CircuitBreaker. Zero fields use defaults.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit, 5 by default
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before probing, 30s by default
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls is the number of concurrent probes allowed while half-open, 1 by default
+	HalfOpenMaxCalls int
+	// IsFailure reports whether an error counts as a database failure. By default every error
+	// counts, connection errors and timeouts among others, except those caused by the call:
+	// not found, constraint violations such as ErrDuplicateKey, invalid input such as
+	// ErrInvalidFilter or ValidationError, read-only transactions and cancelled contexts.
+	IsFailure func(err error) bool
+}
+
+// CircuitBreaker fails repository operations fast once the database keeps failing, instead of
+// piling up calls on a dead connection pool. It opens after FailureThreshold consecutive
+// failures, rejects operations with ErrCircuitOpen for OpenTimeout, then lets probes through:
+// a successful probe closes the circuit, a failed one opens it again.
+// A single breaker can be shared by the repositories of a database.
+type CircuitBreaker struct {
+	config   CircuitBreakerConfig
+	mutex    sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int
+	now      func() time.Time
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenMaxCalls <= 0 {
+		config.HalfOpenMaxCalls = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isDatabaseFailure
+	}
+
+	return &CircuitBreaker{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// WithCircuitBreaker runs the repository methods through breaker
+func WithCircuitBreaker(breaker *CircuitBreaker) RepositoryOption {
+	return WithInterceptors(breaker.Interceptor())
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State() CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.currentState()
+}
+
+// Interceptor returns the interceptor guarding repository operations with the breaker
+func (b *CircuitBreaker) Interceptor() Interceptor {
+	return func(ctx context.Context, op OperationInfo, next func() error) error {
+		probe, err := b.allow()
+		if err != nil {
+			return err
+		}
+
+		err = next()
+		b.record(probe, err)
+		return err
+	}
+}
+
+// allow reports whether an operation may run, and whether it runs as a half-open probe
+func (b *CircuitBreaker) allow() (bool, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.currentState() {
+	case CircuitOpen:
+		return false, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.config.HalfOpenMaxCalls {
+			return false, ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probes++
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record updates the breaker with the outcome of an operation
+func (b *CircuitBreaker) record(probe bool, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if probe {
+		b.probes--
+	}
+
+	if err == nil || !b.config.IsFailure(err) {
+		if probe || b.state == CircuitClosed {
+			b.state = CircuitClosed
+			b.failures = 0
+		}
+		return
+	}
+
+	b.failures++
+	if probe || b.failures >= b.config.FailureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// currentState returns the state of the breaker, open circuits turning half-open once
+// OpenTimeout elapsed. The mutex must be held.
+func (b *CircuitBreaker) currentState() CircuitState {
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// clientErrors are the errors caused by the call rather than the database: missing rows,
+// constraint violations and rejected input. They don't count as database failures, so
+// duplicate signups or bad query strings can't open the circuit for every caller.
+var clientErrors = []error{
+	gorm.ErrRecordNotFound,
+	context.Canceled,
+	ErrNotFound,
+	ErrDuplicateKey,
+	ErrForeignKeyViolation,
+	ErrCheckViolation,
+	ErrReadOnlyTransaction,
+	ErrInvalidPagination,
+	ErrInvalidFilter,
+	ErrInvalidSort,
+	ErrInvalidTableName,
+	ErrUnsupportedQuery,
+	gorm.ErrDuplicatedKey,
+	gorm.ErrForeignKeyViolated,
+	gorm.ErrCheckConstraintViolated,
+	gorm.ErrMissingWhereClause,
+	gorm.ErrUnsupportedRelation,
+	gorm.ErrPrimaryKeyRequired,
+	gorm.ErrInvalidData,
+	gorm.ErrInvalidField,
+	gorm.ErrInvalidValue,
+	gorm.ErrInvalidValueOfLength,
+}
+
+// isDatabaseFailure is the default CircuitBreakerConfig.IsFailure
+func isDatabaseFailure(err error) bool {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return false
+	}
+	for _, clientErr := range clientErrors {
+		if errors.Is(err, clientErr) {
+			return false
+		}
+	}
+	return true
+}
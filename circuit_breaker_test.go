@@ -0,0 +1,114 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	breaker.now = func() time.Time { return now }
+	intercept := breaker.Interceptor()
+	ctx := context.Background()
+	op := OperationInfo{Method: "FindById"}
+
+	outage := errors.New("connection refused")
+	failing := func() error { return outage }
+	calls := 0
+	succeeding := func() error {
+		calls++
+		return nil
+	}
+
+	require.ErrorIs(t, intercept(ctx, op, func() error { return gorm.ErrRecordNotFound }), gorm.ErrRecordNotFound)
+	require.ErrorIs(t, intercept(ctx, op, failing), outage)
+	require.Equal(t, CircuitClosed, breaker.State(), "Expected the circuit to stay closed below the threshold")
+	require.ErrorIs(t, intercept(ctx, op, failing), outage)
+	require.Equal(t, CircuitOpen, breaker.State())
+
+	require.ErrorIs(t, intercept(ctx, op, succeeding), ErrCircuitOpen, "Expected operations to fail fast while open")
+	require.Zero(t, calls)
+
+	now = now.Add(time.Minute)
+	require.Equal(t, CircuitHalfOpen, breaker.State())
+	require.ErrorIs(t, intercept(ctx, op, failing), outage)
+	require.Equal(t, CircuitOpen, breaker.State(), "Expected a failed probe to reopen the circuit")
+
+	now = now.Add(time.Minute)
+	require.NoError(t, intercept(ctx, op, succeeding))
+	require.Equal(t, 1, calls)
+	require.Equal(t, CircuitClosed, breaker.State(), "Expected a successful probe to close the circuit")
+}
+
+func TestGormRepository_WithCircuitBreaker(t *testing.T) {
+	db := setupTestDB(t)
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1})
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithCircuitBreaker(breaker))
+	ctx := context.Background()
+
+	_, err := repo.FindById(ctx, uuid.New())
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Equal(t, CircuitClosed, breaker.State(), "Expected not found errors not to count as failures")
+
+	_, err = repo.FindMany(ctx, WithQuery(func(db *gorm.DB) *gorm.DB {
+		return db.Where("missing_column = ?", 1)
+	}))
+	require.Error(t, err)
+	require.Equal(t, CircuitOpen, breaker.State())
+
+	_, err = repo.FindMany(ctx)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestIsDatabaseFailure(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		failure bool
+	}{
+		{"connection error", errors.New("dial tcp: connection refused"), true},
+		{"deadline exceeded", &DatabaseError{Kind: ErrOperationCanceled, Err: context.DeadlineExceeded}, true},
+		{"not found", &DatabaseError{Kind: ErrNotFound, Err: gorm.ErrRecordNotFound}, false},
+		{"canceled", &DatabaseError{Kind: ErrOperationCanceled, Err: context.Canceled}, false},
+		{"duplicate key", &DatabaseError{Kind: ErrDuplicateKey, Err: errors.New("unique violation")}, false},
+		{"conflict", &ConflictError{DatabaseError: DatabaseError{Kind: ErrDuplicateKey, Err: errors.New("unique violation")}}, false},
+		{"foreign key violation", &DatabaseError{Kind: ErrForeignKeyViolation, Err: errors.New("fk violation")}, false},
+		{"check violation", &DatabaseError{Kind: ErrCheckViolation, Err: errors.New("check violation")}, false},
+		{"read-only transaction", &DatabaseError{Kind: ErrReadOnlyTransaction, Err: ErrReadOnlyTransaction}, false},
+		{"invalid pagination", fmt.Errorf("%w: page 0", ErrInvalidPagination), false},
+		{"invalid filter", fmt.Errorf("%w: field %q", ErrInvalidFilter, "secret"), false},
+		{"invalid sort", fmt.Errorf("%w: %q", ErrInvalidSort, "secret"), false},
+		{"invalid table name", fmt.Errorf("%w: %q", ErrInvalidTableName, "users;"), false},
+		{"validation", &ValidationError{}, false},
+		{"gorm duplicated key", gorm.ErrDuplicatedKey, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.failure, isDatabaseFailure(tc.err))
+		})
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1})
+	intercept := breaker.Interceptor()
+	ctx := context.Background()
+	op := OperationInfo{Method: "Create"}
+
+	duplicate := &ConflictError{DatabaseError: DatabaseError{Kind: ErrDuplicateKey, Err: errors.New("unique violation")}}
+	for i := 0; i < 3; i++ {
+		require.ErrorIs(t, intercept(ctx, op, func() error { return duplicate }), ErrDuplicateKey)
+		require.ErrorIs(t, intercept(ctx, op, func() error { return fmt.Errorf("%w: field %q", ErrInvalidFilter, "x") }), ErrInvalidFilter)
+	}
+	require.Equal(t, CircuitClosed, breaker.State(), "Expected client errors not to open the circuit")
+}
@@ -0,0 +1,81 @@
+package gormrepository
+
+import (
+	"gorm.io/gorm"
+)
+
+// Column describes a field of an entity holding values of type V, for type-checked conditions
+// instead of "email = ?" strings. The field is the Go field name (or the column name) and is
+// resolved through the GORM schema when the SQL is built, so the naming strategy of the
+// connection applies. Column descriptors are meant to be generated next to Diff and Clone:
+//
+//	var UserColumns = struct {
+//		Email gr.Column[string]
+//		Age   gr.Column[int]
+//	}{
+//		Email: gr.NewColumn[string]("Email"),
+//		Age:   gr.NewColumn[int]("Age"),
+//	}
+//
+//	users, err := userRepo.FindMany(ctx, gr.WithSpecification(UserColumns.Email.Eq("x")))
+type Column[V any] struct {
+	field string
+}
+
+// NewColumn returns the descriptor of field
+func NewColumn[V any](field string) Column[V] {
+	return Column[V]{field: field}
+}
+
+// Field returns the field the column describes
+func (c Column[V]) Field() string {
+	return c.field
+}
+
+func (c Column[V]) Eq(value V) Specification {
+	return c.condition("eq", value)
+}
+
+func (c Column[V]) Ne(value V) Specification {
+	return c.condition("ne", value)
+}
+
+func (c Column[V]) Gt(value V) Specification {
+	return c.condition("gt", value)
+}
+
+func (c Column[V]) Gte(value V) Specification {
+	return c.condition("gte", value)
+}
+
+func (c Column[V]) Lt(value V) Specification {
+	return c.condition("lt", value)
+}
+
+func (c Column[V]) Lte(value V) Specification {
+	return c.condition("lte", value)
+}
+
+func (c Column[V]) In(values ...V) Specification {
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return c.condition("in", list)
+}
+
+func (c Column[V]) IsNull() Specification {
+	return c.condition("isnull", true)
+}
+
+func (c Column[V]) IsNotNull() Specification {
+	return c.condition("isnull", false)
+}
+
+// condition builds the same expressions as WithFilters, resolving the field against the schema
+func (c Column[V]) condition(operator string, value interface{}) Specification {
+	expression := filterExpression{field: c.field, operator: operator, value: value}
+	return SpecificationFunc(func(db *gorm.DB) *gorm.DB {
+		return db.Where(expression)
+	})
+}
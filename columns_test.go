@@ -0,0 +1,52 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testUserColumns = struct {
+	Name     Column[string]
+	Age      Column[int]
+	WhatsApp Column[*tests.WhatsAppData]
+}{
+	Name:     NewColumn[string]("Name"),
+	Age:      NewColumn[int]("Age"),
+	WhatsApp: NewColumn[*tests.WhatsAppData]("WhatsAppData"),
+}
+
+func TestColumn_Conditions(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	for _, user := range []*tests.TestUser{
+		{Name: "Alice", Email: "alice@example.com", Age: 17},
+		{Name: "Bob", Email: "bob@example.com", Age: 30, WhatsAppData: &tests.WhatsAppData{DriverId: "driver"}},
+		{Name: "Carol", Email: "carol@example.com", Age: 45},
+	} {
+		require.NoError(t, repo.Create(ctx, user))
+	}
+
+	names := func(spec Specification) []string {
+		users, err := repo.FindMany(ctx, WithSpecification(spec), WithQuery(func(db *gorm.DB) *gorm.DB {
+			return db.Order("name")
+		}))
+		require.NoError(t, err)
+		var names []string
+		for _, user := range users {
+			names = append(names, user.Name)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{"Bob"}, names(testUserColumns.Name.Eq("Bob")))
+	require.Equal(t, []string{"Bob", "Carol"}, names(testUserColumns.Age.Gte(18)))
+	require.Equal(t, []string{"Alice", "Carol"}, names(testUserColumns.Name.In("Alice", "Carol")))
+	require.Equal(t, []string{"Bob"}, names(testUserColumns.WhatsApp.IsNotNull()), "Expected the field to be resolved to its column")
+	require.Equal(t, []string{"Carol"}, names(And(testUserColumns.WhatsApp.IsNull(), testUserColumns.Age.Gt(18))))
+}
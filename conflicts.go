@@ -0,0 +1,122 @@
+package gormrepository
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ConflictError is the DatabaseError of a unique constraint violation, carrying the columns of
+// the violated constraint and the matching entity fields, e.g. "email", so APIs can report
+// which value is already taken. It matches ErrDuplicateKey with errors.Is.
+type ConflictError struct {
+	DatabaseError
+	// Constraint is the name of the violated constraint, when the driver reports it
+	Constraint string
+	// Columns are the database columns of the constraint
+	Columns []string
+	// Fields are the entity fields of Columns, by JSON name (or column name when unknown)
+	Fields []string
+}
+
+// As lets errors.As find the DatabaseError of the conflict
+func (e *ConflictError) As(target interface{}) bool {
+	if databaseErr, ok := target.(**DatabaseError); ok {
+		*databaseErr = &e.DatabaseError
+		return true
+	}
+	return false
+}
+
+var (
+	// postgresKeyDetail matches the detail of Postgres unique violations: Key (email)=(a@b.c) already exists.
+	postgresKeyDetail = regexp.MustCompile(`^Key \((.+?)\)=\(`)
+	// sqliteUniqueFailure matches SQLite unique violations: UNIQUE constraint failed: users.email
+	sqliteUniqueFailure = regexp.MustCompile(`UNIQUE constraint failed: (.+)$`)
+)
+
+// conflictError describes the unique constraint violation err
+func (r *GormRepository[T]) conflictError(err error) *ConflictError {
+	conflict := &ConflictError{DatabaseError: DatabaseError{Kind: ErrDuplicateKey, Err: err}}
+
+	var entitySchema *schema.Schema
+	stmt := &gorm.Statement{DB: r.DB}
+	if parseErr := stmt.Parse(new(T)); parseErr == nil {
+		entitySchema = stmt.Schema
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		conflict.Constraint = pgErr.ConstraintName
+		if match := postgresKeyDetail.FindStringSubmatch(pgErr.Detail); match != nil {
+			conflict.Columns = strings.Split(match[1], ", ")
+		} else {
+			conflict.Columns = constraintColumns(entitySchema, pgErr.ConstraintName)
+		}
+	} else if match := sqliteUniqueFailure.FindStringSubmatch(err.Error()); match != nil {
+		for _, column := range strings.Split(match[1], ", ") {
+			conflict.Columns = append(conflict.Columns, column[strings.LastIndex(column, ".")+1:])
+		}
+	}
+
+	for _, column := range conflict.Columns {
+		conflict.Fields = append(conflict.Fields, fieldName(entitySchema, column))
+	}
+
+	return conflict
+}
+
+// constraintColumns returns the columns of the unique constraint name of s, declared as a
+// unique index or following the Postgres <table>_<column>_key naming of unique columns
+func constraintColumns(s *schema.Schema, name string) []string {
+	if s == nil || name == "" {
+		return nil
+	}
+
+	for _, index := range s.ParseIndexes() {
+		if index.Name == name {
+			columns := make([]string, len(index.Fields))
+			for i, option := range index.Fields {
+				columns[i] = option.DBName
+			}
+			return columns
+		}
+	}
+
+	for _, field := range s.Fields {
+		if field.DBName != "" && name == s.Table+"_"+field.DBName+"_key" {
+			return []string{field.DBName}
+		}
+	}
+
+	return nil
+}
+
+// fieldName returns the JSON name of the field stored in column, or column when it isn't a
+// field of s
+func fieldName(s *schema.Schema, column string) string {
+	if s == nil {
+		return column
+	}
+
+	field := s.LookUpField(column)
+	if field == nil {
+		return column
+	}
+
+	return jsonFieldName(field.StructField)
+}
+
+// jsonFieldName returns the JSON name of a struct field
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,124 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned by the repository methods in place of driver specific errors. They are
+// matched with errors.Is; the original error stays available through errors.Unwrap.
+var (
+	ErrNotFound            = errors.New("record not found")
+	ErrDuplicateKey        = errors.New("duplicate key")
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	ErrCheckViolation      = errors.New("check constraint violation")
+	// ErrOperationCanceled is returned when the context of a call is cancelled or its deadline
+	// exceeded, wrapping context.Canceled or context.DeadlineExceeded
+	ErrOperationCanceled = errors.New("operation canceled")
+	// ErrReadOnlyTransaction is returned by writes within a read-only transaction
+	ErrReadOnlyTransaction = errors.New("write in read-only transaction")
+)
+
+// Postgres error codes translated into the errors above
+const (
+	sqlStateUniqueViolation     = "23505"
+	sqlStateForeignKeyViolation = "23503"
+	sqlStateCheckViolation      = "23514"
+	sqlStateQueryCanceled       = "57014"
+	sqlStateReadOnlyTransaction = "25006"
+)
+
+// DatabaseError is a database error classified as one of the package errors. Its message is
+// the message of the original error.
+type DatabaseError struct {
+	// Kind is the package error matching the original error, e.g. ErrDuplicateKey
+	Kind error
+	// Err is the error returned by GORM or the driver
+	Err error
+}
+
+func (e *DatabaseError) Error() string {
+	return e.Err.Error()
+}
+
+// Is reports whether target is the kind of the error
+func (e *DatabaseError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the original error
+func (e *DatabaseError) Unwrap() error {
+	return e.Err
+}
+
+// translateError wraps err into a DatabaseError when it matches one of the package errors,
+// a ConflictError for unique violations.
+// Postgres errors are matched by SQLSTATE, others through the error translation of the dialect.
+func (r *GormRepository[T]) translateError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Drivers don't always wrap the context error, e.g. when Postgres cancels the statement
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		err = fmt.Errorf("%w: %w", ctxErr, err)
+	}
+
+	var databaseErr *DatabaseError
+	if errors.As(err, &databaseErr) {
+		return err
+	}
+
+	switch kind := r.errorKind(err); kind {
+	case nil:
+		return err
+	case ErrDuplicateKey:
+		return r.conflictError(err)
+	default:
+		return &DatabaseError{Kind: kind, Err: err}
+	}
+}
+
+// errorKind returns the package error matching err, or nil
+func (r *GormRepository[T]) errorKind(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrOperationCanceled
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		switch state.SQLState() {
+		case sqlStateUniqueViolation:
+			return ErrDuplicateKey
+		case sqlStateForeignKeyViolation:
+			return ErrForeignKeyViolation
+		case sqlStateCheckViolation:
+			return ErrCheckViolation
+		case sqlStateQueryCanceled:
+			return ErrOperationCanceled
+		case sqlStateReadOnlyTransaction:
+			return ErrReadOnlyTransaction
+		}
+	}
+
+	translated := err
+	if translator, ok := r.DB.Dialector.(gorm.ErrorTranslator); ok {
+		translated = translator.Translate(err)
+	}
+	switch {
+	case errors.Is(translated, gorm.ErrDuplicatedKey):
+		return ErrDuplicateKey
+	case errors.Is(translated, gorm.ErrForeignKeyViolated):
+		return ErrForeignKeyViolation
+	case errors.Is(translated, gorm.ErrCheckConstraintViolated):
+		return ErrCheckViolation
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormRepository_TranslatesErrors(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	_, err := repo.FindById(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound, "Expected the original error to be preserved")
+	require.Equal(t, gorm.ErrRecordNotFound, errors.Unwrap(err))
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "unique"}
+	require.NoError(t, repo.Create(ctx, entity), "Create should not fail")
+	err = repo.Create(ctx, &tests.TestSimpleEntity{Id: entity.Id, Value: "duplicate"})
+	require.ErrorIs(t, err, ErrDuplicateKey)
+
+	var databaseErr *DatabaseError
+	require.ErrorAs(t, err, &databaseErr)
+	require.NotNil(t, errors.Unwrap(err), "Expected the driver error to be preserved")
+}
+
+func TestGormRepository_TranslateError_SQLState(t *testing.T) {
+	repo := &GormRepository[tests.TestSimpleEntity]{DB: setupTestDB(t)}
+
+	cases := []struct {
+		code string
+		kind error
+	}{
+		{code: "23505", kind: ErrDuplicateKey},
+		{code: "23503", kind: ErrForeignKeyViolation},
+		{code: "23514", kind: ErrCheckViolation},
+	}
+	for _, c := range cases {
+		original := fmt.Errorf("insert: %w", sqlStateError(c.code))
+		err := repo.translateError(context.Background(), original)
+		require.ErrorIs(t, err, c.kind, c.code)
+		require.Equal(t, original, errors.Unwrap(err))
+	}
+
+	other := sqlStateError("42P01")
+	require.Equal(t, error(other), repo.translateError(context.Background(), other), "Expected other errors to be returned unchanged")
+}
+
+type testContact struct {
+	Id           uuid.UUID `gorm:"type:text;primary_key" json:"id"`
+	EmailAddress string    `gorm:"uniqueIndex:idx_test_contact_email" json:"emailAddress"`
+}
+
+func TestGormRepository_UniqueViolation_ConflictError(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&testContact{}), "Failed to migrate contact entity")
+
+	repo := NewGormRepository[testContact](db)
+	ctx := context.Background()
+
+	email := uuid.NewString() + "@example.com"
+	require.NoError(t, repo.Create(ctx, &testContact{Id: uuid.New(), EmailAddress: email}), "Create should not fail")
+
+	err := repo.Create(ctx, &testContact{Id: uuid.New(), EmailAddress: email})
+	require.ErrorIs(t, err, ErrDuplicateKey)
+
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, []string{"email_address"}, conflict.Columns)
+	require.Equal(t, []string{"emailAddress"}, conflict.Fields)
+
+	var databaseErr *DatabaseError
+	require.ErrorAs(t, err, &databaseErr, "Expected conflicts to be database errors")
+	require.Equal(t, ErrDuplicateKey, databaseErr.Kind)
+}
+
+func TestGormRepository_ConflictError_PostgresConstraint(t *testing.T) {
+	repo := NewGormRepository[testContact](setupTestDB(t))
+
+	withDetail := &pgconn.PgError{Code: "23505", ConstraintName: "idx_test_contact_email", Detail: "Key (email_address)=(a@example.com) already exists."}
+	withoutDetail := &pgconn.PgError{Code: "23505", ConstraintName: "idx_test_contact_email"}
+
+	for _, pgErr := range []*pgconn.PgError{withDetail, withoutDetail} {
+		var conflict *ConflictError
+		require.ErrorAs(t, repo.translateError(context.Background(), pgErr), &conflict)
+		require.Equal(t, "idx_test_contact_email", conflict.Constraint)
+		require.Equal(t, []string{"email_address"}, conflict.Columns)
+		require.Equal(t, []string{"emailAddress"}, conflict.Fields)
+	}
+}
+
+func TestGormRepository_OperationCanceled(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "canceled"}
+	err := repo.Create(ctx, entity)
+	require.ErrorIs(t, err, ErrOperationCanceled)
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = repo.FindById(context.Background(), entity.Id)
+	require.ErrorIs(t, err, ErrNotFound, "Expected the canceled Create not to run")
+
+	_, err = repo.FindMany(context.Background(), WithTimeout(time.Nanosecond))
+	require.ErrorIs(t, err, ErrOperationCanceled)
+	require.ErrorIs(t, err, context.DeadlineExceeded, "Expected timeouts to be distinguishable from cancellations")
+	require.NotErrorIs(t, err, context.Canceled)
+}
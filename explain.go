@@ -0,0 +1,137 @@
+package gormrepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+)
+
+// ExplainAnalyze is the Postgres EXPLAIN statement used by WithExplainEnabled, running the query
+// to report actual timings and buffer usage
+const ExplainAnalyze = "EXPLAIN (ANALYZE, BUFFERS)"
+
+const (
+	explainContextKey   = "__explain"
+	explainerContextKey = "__explainer"
+	explainCallbackName = "gorm_repository:explain"
+)
+
+// explainer runs the explain statement of a repository and logs the plans
+type explainer struct {
+	statement string
+	logger    Logger
+}
+
+// WithExplainEnabled allows WithExplain on the repository: queries using it are first run
+// prefixed with explain (ExplainAnalyze when empty) and the plan is logged at info level with
+// the repository Logger, then executed as usual. Without this option WithExplain does nothing,
+// so it can't slow down production deployments by accident.
+// The option registers a query callback on the GORM connection, so the repository should be
+// created before the connection is used concurrently.
+func WithExplainEnabled(explain string) RepositoryOption {
+	return func(config *repositoryConfig) {
+		if explain == "" {
+			explain = ExplainAnalyze
+		}
+		config.explain = explain
+	}
+}
+
+// WithExplain returns an option logging the query plan of a read (FindMany, FindPaginated,
+// FindOne, FindById) on repositories created with WithExplainEnabled
+func WithExplain() Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(explainContextKey, true)
+	}
+}
+
+// registerExplainCallback installs the explain callback on the query callbacks of db, once
+func registerExplainCallback(db *gorm.DB) error {
+	queries := db.Callback().Query()
+	if queries.Get(explainCallbackName) != nil {
+		return nil
+	}
+	return queries.Before("gorm:query").Register(explainCallbackName, explainQuery)
+}
+
+// enableExplain hands the explainer of the repository to the statement of db when the call
+// requested WithExplain
+func (r *GormRepository[T]) enableExplain(db *gorm.DB) {
+	if r.config.explain == "" || r.config.logger == nil {
+		return
+	}
+	if requested, _ := db.Get(explainContextKey); requested != true {
+		return
+	}
+
+	db.Statement.Settings.Store(explainerContextKey, &explainer{
+		statement: r.config.explain,
+		logger:    r.config.logger,
+	})
+}
+
+// explainQuery builds the SQL of a query having an explainer and logs its plan. The query
+// callback then runs the already built SQL.
+func explainQuery(db *gorm.DB) {
+	value, ok := db.Get(explainerContextKey)
+	if !ok || db.Error != nil || db.DryRun {
+		return
+	}
+	e := value.(*explainer)
+
+	callbacks.BuildQuerySQL(db)
+	if db.Error != nil {
+		return
+	}
+
+	query := db.Statement.SQL.String()
+	plan, err := e.explain(db.Statement.Context, db.Statement.ConnPool, query, db.Statement.Vars)
+	if err != nil {
+		e.logger.Log(db.Statement.Context, LogLevelWarn, "explain failed", Field{Key: "sql", Value: query}, Field{Key: "error", Value: err})
+		return
+	}
+	e.logger.Log(db.Statement.Context, LogLevelInfo, "query plan", Field{Key: "sql", Value: query}, Field{Key: "plan", Value: plan})
+}
+
+// explain runs the explain statement for query and returns the plan, one line per row
+func (e *explainer) explain(ctx context.Context, pool gorm.ConnPool, query string, vars []interface{}) (string, error) {
+	rows, err := pool.QueryContext(ctx, e.statement+" "+query, vars...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		targets := make([]interface{}, len(columns))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return "", err
+		}
+
+		parts := make([]string, 0, len(values))
+		for _, value := range values {
+			if value.Valid {
+				parts = append(parts, value.String)
+			}
+		}
+		lines = append(lines, strings.Join(parts, " "))
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("read plan: %w", err)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,64 @@
+package gormrepository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger keeps the messages logged by a repository
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+	fields   []map[string]interface{}
+}
+
+func (l *recordingLogger) Log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	values := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		values[field.Key] = field.Value
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, msg)
+	l.fields = append(l.fields, values)
+}
+
+func TestGormRepository_WithExplain(t *testing.T) {
+	db := setupTestDB(t)
+	logger := &recordingLogger{}
+	explain := ExplainAnalyze
+	if db.Dialector.Name() == "sqlite" {
+		explain = "EXPLAIN QUERY PLAN"
+	}
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithLogger(logger), WithExplainEnabled(explain))
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "explained"}
+	require.NoError(t, db.Create(entity).Error)
+
+	found, err := repo.FindById(ctx, entity.Id, WithExplain())
+	require.NoError(t, err, "FindById with WithExplain should not fail")
+	require.Equal(t, entity.Value, found.Value, "Expected the query to run after the explain")
+
+	var plans []map[string]interface{}
+	for i, msg := range logger.messages {
+		if msg == "query plan" {
+			plans = append(plans, logger.fields[i])
+		}
+	}
+	require.Len(t, plans, 1)
+	require.Contains(t, plans[0]["sql"], "test_simple_entities")
+	require.NotEmpty(t, plans[0]["plan"])
+
+	disabled := NewGormRepository[tests.TestSimpleEntity](db, WithLogger(logger))
+	count := len(logger.messages)
+	_, err = disabled.FindById(ctx, entity.Id, WithExplain())
+	require.NoError(t, err)
+	require.Len(t, logger.messages, count+1, "Expected WithExplain to be ignored unless enabled")
+}
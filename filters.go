@@ -0,0 +1,139 @@
+package gormrepository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidFilter is returned by the calls using WithFilters with a field that isn't allowed,
+// isn't part of the entity or uses an unknown operator
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// filterOperators maps the operator suffixes accepted by WithFilters to their SQL operators
+var filterOperators = map[string]string{
+	"eq":  "=",
+	"ne":  "<>",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+	"in":  "IN",
+}
+
+// WithFilters returns an option converting request parameters into WHERE conditions. Keys are
+// field names with an optional operator suffix: age__gte=18, status__in=a,b (or a slice),
+// deletedAt__isnull=true, plain keys testing equality. Fields must be listed in allowedFields
+// and exist on the entity, as Go field or column name; other keys fail the call with
+// ErrInvalidFilter. Values are always bound as parameters.
+func WithFilters(filters map[string]interface{}, allowedFields []string) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		allowed := make(map[string]bool, len(allowedFields))
+		for _, field := range allowedFields {
+			allowed[field] = true
+		}
+
+		// Sorted for deterministic SQL
+		keys := make([]string, 0, len(filters))
+		for key := range filters {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			field, operator, _ := strings.Cut(key, "__")
+			if operator == "" {
+				operator = "eq"
+			}
+			if !allowed[field] {
+				db = db.Session(&gorm.Session{})
+				db.AddError(fmt.Errorf("%w: field %q", ErrInvalidFilter, field))
+				return db
+			}
+			if _, ok := filterOperators[operator]; !ok && operator != "isnull" {
+				db = db.Session(&gorm.Session{})
+				db.AddError(fmt.Errorf("%w: operator %q", ErrInvalidFilter, operator))
+				return db
+			}
+
+			db = db.Where(filterExpression{field: field, operator: operator, value: filters[key]})
+		}
+		return db
+	}
+}
+
+// filterExpression is a WithFilters condition. The field is resolved against the schema of the
+// statement when the SQL is built, as the model isn't known when options are applied.
+type filterExpression struct {
+	field    string
+	operator string
+	value    interface{}
+}
+
+func (f filterExpression) Build(builder clause.Builder) {
+	schemaField := lookUpField(builder, f.field)
+	if schemaField == nil {
+		return
+	}
+
+	if f.operator == "in" {
+		values := filterValues(f.value)
+		if len(values) == 0 {
+			// IN () isn't valid SQL: an empty list matches no row
+			builder.WriteString("1 = 0")
+			return
+		}
+		builder.WriteQuoted(clause.Column{Table: clause.CurrentTable, Name: schemaField.DBName})
+		builder.WriteString(" IN (")
+		builder.AddVar(builder, values...)
+		builder.WriteByte(')')
+		return
+	}
+
+	builder.WriteQuoted(clause.Column{Table: clause.CurrentTable, Name: schemaField.DBName})
+	switch f.operator {
+	case "isnull":
+		if isNull, _ := f.value.(bool); isNull || f.value == "true" {
+			builder.WriteString(" IS NULL")
+		} else {
+			builder.WriteString(" IS NOT NULL")
+		}
+	default:
+		builder.WriteString(" " + filterOperators[f.operator] + " ")
+		builder.AddVar(builder, f.value)
+	}
+}
+
+// lookUpField resolves field, a Go field or column name, against the schema of the statement
+// built, adding ErrInvalidFilter to it when the field isn't part of the entity
+func lookUpField(builder clause.Builder, field string) *schema.Field {
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok || stmt.Schema == nil {
+		builder.AddError(fmt.Errorf("%w: field %q without schema", ErrInvalidFilter, field))
+		return nil
+	}
+	schemaField := stmt.Schema.LookUpField(field)
+	if schemaField == nil || schemaField.DBName == "" {
+		builder.AddError(fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, field))
+		return nil
+	}
+	return schemaField
+}
+
+// filterValues returns the values of an IN filter: a comma separated string, or any slice or
+// array but []byte and driver.Valuer types such as uuid.UUID, which are single values
+func filterValues(value interface{}) []interface{} {
+	switch value := value.(type) {
+	case string:
+		return filterValues(strings.Split(value, ","))
+	case []byte:
+		return []interface{}{value}
+	default:
+		return sliceValues(value)
+	}
+}
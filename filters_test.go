@@ -0,0 +1,94 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormRepository_WithFilters(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	for _, user := range []*tests.TestUser{
+		{Name: "Alice", Email: "alice@example.com", Age: 17, Active: true},
+		{Name: "Bob", Email: "bob@example.com", Age: 30, Active: true},
+		{Name: "Carol", Email: "carol@example.com", Age: 45},
+	} {
+		require.NoError(t, repo.Create(ctx, user))
+	}
+
+	allowed := []string{"name", "age", "Active"}
+	names := func(users []*tests.TestUser) []string {
+		var names []string
+		for _, user := range users {
+			names = append(names, user.Name)
+		}
+		return names
+	}
+
+	users, err := repo.FindMany(ctx, WithFilters(map[string]interface{}{"age__gte": 18, "Active": true}, allowed))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Bob"}, names(users))
+
+	users, err = repo.FindMany(ctx, WithFilters(map[string]interface{}{"name__in": "Alice,Carol"}, allowed), WithQuery(func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice", "Carol"}, names(users))
+
+	page, err := repo.FindPaginated(ctx, 1, 10, WithFilters(map[string]interface{}{"age__lt": 40}, allowed))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), page.Total)
+
+	_, err = repo.FindMany(ctx, WithFilters(map[string]interface{}{"email": "bob@example.com"}, allowed))
+	require.ErrorIs(t, err, ErrInvalidFilter, "Expected fields outside of the whitelist to fail")
+
+	_, err = repo.FindMany(ctx, WithFilters(map[string]interface{}{"age__between": 1}, allowed))
+	require.ErrorIs(t, err, ErrInvalidFilter, "Expected unknown operators to fail")
+
+	_, err = repo.FindMany(ctx, WithFilters(map[string]interface{}{"missing": 1}, []string{"missing"}))
+	require.ErrorIs(t, err, ErrInvalidFilter, "Expected fields missing from the entity to fail")
+}
+
+func TestWithFilters_InValues(t *testing.T) {
+	db := dryRunPostgres(t)
+	allowed := []string{"age", "id"}
+	id := uuid.New()
+
+	testCases := []struct {
+		name         string
+		filters      map[string]interface{}
+		expectedSQL  string
+		expectedVars []interface{}
+	}{
+		{"ints", map[string]interface{}{"age__in": []int{1, 2}}, `"test_users"."age" IN ($1,$2)`, []interface{}{1, 2}},
+		{"array", map[string]interface{}{"age__in": [2]int64{3, 4}}, `"test_users"."age" IN ($1,$2)`, []interface{}{int64(3), int64(4)}},
+		{"uuid", map[string]interface{}{"id__in": id}, `"test_users"."id" IN ($1)`, []interface{}{id}},
+		{"empty", map[string]interface{}{"age__in": []int{}}, `1 = 0`, []interface{}{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var users []tests.TestUser
+			stmt := applyOptions(db.Model(&tests.TestUser{}), []Option{WithFilters(tc.filters, allowed)}).Find(&users).Statement
+			require.NoError(t, stmt.Error)
+			require.Contains(t, stmt.SQL.String(), "WHERE "+tc.expectedSQL)
+			require.Equal(t, tc.expectedVars, stmt.Vars)
+		})
+	}
+
+	repo := NewGormRepository[tests.TestUser](setupTestDB(t))
+	require.NoError(t, repo.Create(context.Background(), &tests.TestUser{Name: "Alice", Email: "alice@example.com", Age: 17}))
+	users, err := repo.FindMany(context.Background(), WithFilters(map[string]interface{}{"age__in": []int{}}, allowed))
+	require.NoError(t, err)
+	require.Empty(t, users, "Expected an empty list to match no row")
+	users, err = repo.FindMany(context.Background(), WithSpecification(Not(NewColumn[int]("age").In())))
+	require.NoError(t, err)
+	require.Len(t, users, 1, "Expected the negated empty list to match every row")
+}
@@ -2,12 +2,15 @@ package gormrepository
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -25,16 +28,61 @@ const (
 var jsonColumnTypeCache sync.Map
 
 type GormRepository[T any] struct {
-	Repository[T]
-	DB *gorm.DB
+	DB     *gorm.DB
+	config repositoryConfig
+	hooks  map[HookEvent][]Hook[T]
+	stats  operationStatsRecorder
+}
+
+// RepositoryOption configures optional behavior of a GormRepository at construction time.
+type RepositoryOption func(*repositoryConfig)
+
+// repositoryConfig holds the optional repository behavior. The zero value disables every
+// optional feature, so repositories built as struct literals keep working.
+type repositoryConfig struct {
+	auditRecorder     AuditRecorder
+	actorExtractor    ActorExtractor
+	changePublisher   ChangePublisher
+	interceptors      []Interceptor
+	validator         Validator
+	normalizers       []Normalizer
+	managedTimestamps bool
+	idGenerator       IDGenerator
+	txBeginHooks      []TxBeginHook
+	txEndHooks        []TxEndHook
+	replicas          *replicaSet
+	prepareStmt       bool
+	logger            Logger
+	sqlComments       *sqlCommentConfig
+	explain           string
+	snapshotLimit     int
+	identityMap       bool
+	parallelPaging    bool
+	defaultPageSize   int
+	maxPageSize       int
+	tableRouter       TableRouter
 }
 
 // NewGormRepository creates a new instance of GormRepository with the provided GORM database connection.
 // T is the entity type that this repository will manage.
-func NewGormRepository[T any](db *gorm.DB) *GormRepository[T] {
-	return &GormRepository[T]{
+func NewGormRepository[T any](db *gorm.DB, opts ...RepositoryOption) *GormRepository[T] {
+	repo := &GormRepository[T]{
 		DB: db,
 	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&repo.config)
+		}
+	}
+	if repo.config.prepareStmt {
+		repo.DB = db.Session(&gorm.Session{PrepareStmt: true})
+	}
+	if repo.config.explain != "" {
+		if err := registerExplainCallback(db); err != nil {
+			repo.log(context.Background(), LogLevelWarn, "explain callback registration failed", Field{Key: "error", Value: err})
+		}
+	}
+	return repo
 }
 
 func WithRelations(relations ...string) Option {
@@ -55,6 +103,40 @@ func applyOptions(db *gorm.DB, options []Option) *gorm.DB {
 	return db
 }
 
+// session prepares the statement of a repository call: options are applied, the call is bound
+// to ctx, bounded by WithTimeout, tagged with its SQL comment and explained when requested.
+// cancel must be called once the call is done.
+func (r *GormRepository[T]) session(ctx context.Context, options []Option) (*gorm.DB, context.CancelFunc) {
+	db, cancel := withTimeout(ctx, applyOptions(r.DB, options))
+	r.tagStatement(ctx, db)
+	r.enableExplain(db)
+	r.guardReadOnly(ctx, db)
+	return db, cancel
+}
+
+// guardReadOnly fails the statements of a write running within a read-only transaction,
+// for the dialects that don't enforce it themselves
+func (r *GormRepository[T]) guardReadOnly(ctx context.Context, db *gorm.DB) {
+	op, ok := OperationFromContext(ctx)
+	if !ok || op.ReadOnly {
+		return
+	}
+	if txInterface, inTx := db.Get(txContextKey); inTx {
+		if tx, ok := txInterface.(*Tx); ok && tx.readOnly {
+			db.AddError(&DatabaseError{Kind: ErrReadOnlyTransaction, Err: fmt.Errorf("%s: %w", op.Method, ErrReadOnlyTransaction)})
+		}
+	}
+}
+
+// readOnlyViolation returns the error guardReadOnly added to db, so a write within a read-only
+// transaction is rejected before its hooks, normalization and validation run
+func readOnlyViolation(db *gorm.DB) error {
+	if errors.Is(db.Error, ErrReadOnlyTransaction) {
+		return db.Error
+	}
+	return nil
+}
+
 func newEntity[T any]() T {
 	var entity T
 	entityType := reflect.TypeOf(entity)
@@ -65,45 +147,81 @@ func newEntity[T any]() T {
 }
 
 func (r *GormRepository[T]) FindMany(ctx context.Context, options ...Option) ([]*T, error) {
+	var result []*T
+	err := r.intercept(ctx, "FindMany", true, func(ctx context.Context) (err error) {
+		result, err = r.findMany(ctx, options...)
+		return err
+	})
+	return result, err
+}
+
+func (r *GormRepository[T]) findMany(ctx context.Context, options ...Option) ([]*T, error) {
 	var entities []*T
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	db = r.routeRead(r.routeTable(ctx, db, nil))
 	if err := db.Find(&entities).Error; err != nil {
 		return nil, err
 	}
 
+	// Store clones if in transaction and supports cloning
+	for _, entity := range entities {
+		storeCloneIfInTransaction(db, entity)
+	}
+
 	return entities, nil
 }
 
 // FindPaginated retrieves records with pagination.
 func (r *GormRepository[T]) FindPaginated(ctx context.Context, page int, pageSize int, options ...Option) (*PaginationResult[*T], error) {
-	var entities []*T
-	var totalRows int64
+	var result *PaginationResult[*T]
+	err := r.intercept(ctx, "FindPaginated", true, func(ctx context.Context) (err error) {
+		result, err = r.findPaginated(ctx, page, pageSize, options...)
+		return err
+	})
+	return result, err
+}
+
+func (r *GormRepository[T]) findPaginated(ctx context.Context, page int, pageSize int, options ...Option) (*PaginationResult[*T], error) {
+	pageSize, err := r.pageSize(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
 
-	db := applyOptions(r.DB, options).WithContext(ctx)
-	db.Model(&entities).Count(&totalRows)
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	db = r.routeRead(r.routeTable(ctx, db, nil))
 
 	offset := (page - 1) * pageSize
-	if err := db.Offset(offset).Limit(pageSize).Find(&entities).Error; err != nil {
+	result, err := r.findPage(db, offset, pageSize)
+	if err != nil {
 		return nil, err
 	}
 
-	result := &PaginationResult[*T]{
-		Data:        entities,
-		Total:       totalRows,
-		Limit:       pageSize,
-		Offset:      offset,
-		CurrentPage: page,
-		LastPage:    int((totalRows + int64(pageSize) - 1) / int64(pageSize)),
-		From:        offset + 1,
-		To:          offset + len(entities),
+	// Store clones if in transaction and supports cloning
+	for _, entity := range result.Data {
+		storeCloneIfInTransaction(db, entity)
 	}
 
+	setPageMetadata(result, page, pageSize, offset)
+
 	return result, nil
 }
 
 func (r *GormRepository[T]) FindOne(ctx context.Context, options ...Option) (*T, error) {
+	var result *T
+	err := r.intercept(ctx, "FindOne", true, func(ctx context.Context) (err error) {
+		result, err = r.findOne(ctx, options...)
+		return err
+	})
+	return result, err
+}
+
+func (r *GormRepository[T]) findOne(ctx context.Context, options ...Option) (*T, error) {
 	entity := newEntity[T]()
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	db = r.routeRead(r.routeTable(ctx, db, nil))
 
 	if err := db.First(&entity).Error; err != nil {
 		return nil, err
@@ -116,23 +234,51 @@ func (r *GormRepository[T]) FindOne(ctx context.Context, options ...Option) (*T,
 }
 
 func (r *GormRepository[T]) FindById(ctx context.Context, id uuid.UUID, options ...Option) (*T, error) {
+	var result *T
+	err := r.intercept(ctx, "FindById", true, func(ctx context.Context) (err error) {
+		result, err = r.findById(ctx, id, options...)
+		return err
+	})
+	return result, err
+}
+
+func (r *GormRepository[T]) findById(ctx context.Context, id uuid.UUID, options ...Option) (*T, error) {
 	entity := newEntity[T]()
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	db = r.routeTable(ctx, db, nil)
+	if mapped, found := r.mappedEntity(db, id); found {
+		return mapped, nil
+	}
+
+	db = r.routeRead(db)
 	if err := db.First(&entity, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 
 	// Store clone if in transaction and supports cloning
 	storeCloneIfInTransaction(db, &entity)
+	r.mapEntity(db, &entity)
 
 	return &entity, nil
 }
 
 func (r *GormRepository[T]) Max(ctx context.Context, column string, options ...Option) (int, error) {
+	var result int
+	err := r.intercept(ctx, "Max", true, func(ctx context.Context) (err error) {
+		result, err = r.max(ctx, column, options...)
+		return err
+	})
+	return result, err
+}
+
+func (r *GormRepository[T]) max(ctx context.Context, column string, options ...Option) (int, error) {
 	entity := newEntity[T]()
 	var max *int
 
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	db = r.routeRead(r.routeTable(ctx, db, nil))
 
 	if err := db.Model(&entity).Select("MAX(?)", gorm.Expr(column)).Scan(&max).Error; err != nil {
 		return 0, err
@@ -146,27 +292,167 @@ func (r *GormRepository[T]) Max(ctx context.Context, column string, options ...O
 }
 
 func (r *GormRepository[T]) Create(ctx context.Context, entity *T, options ...Option) error {
-	db := applyOptions(r.DB, options).WithContext(ctx)
-	if err := db.Omit(clause.Associations).Create(entity).Error; err != nil {
+	return r.intercept(ctx, "Create", false, func(ctx context.Context) error {
+		return r.create(ctx, entity, options...)
+	})
+}
+
+func (r *GormRepository[T]) create(ctx context.Context, entity *T, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return err
+	}
+
+	// Entities without an Id get one before anything else, so hooks, audit entries
+	// and events all see the final Id
+	if err := r.assignId(entity); err != nil {
+		return err
+	}
+	if err := r.prepareAssociations(db, entity); err != nil {
+		return err
+	}
+
+	if err := r.beforeWrite(ctx, HookBeforeCreate, entity); err != nil {
+		return err
+	}
+	db = r.routeTable(ctx, db, entity)
+
+	c, err := r.createChange(db, entity)
+	if err != nil {
+		return err
+	}
+
+	err = r.writeTracked(ctx, db, c, func(db *gorm.DB) error {
+		return omitAssociations(db).Create(entity).Error
+	})
+	if err != nil {
 		return err
 	}
 
 	storeCloneIfInTransaction(db, entity)
+	r.mapEntity(db, entity)
+
+	return r.runHooks(ctx, HookAfterCreate, entity)
+}
+
+// CreateMany inserts entities in a single statement, applying the same Id generation,
+// hooks, validation and change tracking as Create to each of them.
+func (r *GormRepository[T]) CreateMany(ctx context.Context, entities []*T, options ...Option) error {
+	return r.intercept(ctx, "CreateMany", false, func(ctx context.Context) error {
+		return r.createMany(ctx, entities, options...)
+	})
+}
+
+func (r *GormRepository[T]) createMany(ctx context.Context, entities []*T, options ...Option) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return err
+	}
+
+	var changes []*change[T]
+	for _, entity := range entities {
+		if err := r.assignId(entity); err != nil {
+			return err
+		}
+		if err := r.prepareAssociations(db, entity); err != nil {
+			return err
+		}
+
+		if err := r.beforeWrite(ctx, HookBeforeCreate, entity); err != nil {
+			return err
+		}
+
+		c, err := r.createChange(db, entity)
+		if err != nil {
+			return err
+		}
+		if c != nil {
+			changes = append(changes, c)
+		}
+	}
+
+	err := r.writeTrackedBatch(ctx, db, changes, func(db *gorm.DB) error {
+		for _, batch := range r.routeBatch(ctx, db, entities) {
+			if err := omitAssociations(batch.db).Create(batch.entities).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, entity := range entities {
+		routed := r.routeTable(ctx, db, entity)
+		storeCloneIfInTransaction(routed, entity)
+		r.mapEntity(routed, entity)
+
+		if err := r.runHooks(ctx, HookAfterCreate, entity); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func (r *GormRepository[T]) Save(ctx context.Context, entity *T, options ...Option) error {
-	db := applyOptions(r.DB, options).WithContext(ctx)
-	return db.Omit(clause.Associations).Save(entity).Error
+	return r.intercept(ctx, "Save", false, func(ctx context.Context) error {
+		return r.save(ctx, entity, options...)
+	})
+}
+
+func (r *GormRepository[T]) save(ctx context.Context, entity *T, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return err
+	}
+
+	if err := r.beforeWrite(ctx, HookBeforeUpdate, entity); err != nil {
+		return err
+	}
+	r.touch(entity)
+	db = r.routeTable(ctx, db, entity)
+	if err := r.prepareAssociations(db, entity); err != nil {
+		return err
+	}
+
+	c, err := r.saveChange(db, entity)
+	if err != nil {
+		return err
+	}
+
+	err = r.writeTracked(ctx, db, c, func(db *gorm.DB) error {
+		return omitAssociations(db).Save(entity).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.runHooks(ctx, HookAfterUpdate, entity)
 }
 
 func (r *GormRepository[T]) BulkUpdate(ctx context.Context, where Option, mask map[string]interface{}, options ...Option) error {
+	return r.intercept(ctx, "BulkUpdate", false, func(ctx context.Context) error {
+		return r.bulkUpdate(ctx, where, mask, options...)
+	})
+}
+
+func (r *GormRepository[T]) bulkUpdate(ctx context.Context, where Option, mask map[string]interface{}, options ...Option) error {
 	if where == nil {
 		return fmt.Errorf("WHERE conditions are required for bulk update")
 	}
 
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	db = r.routeTable(ctx, db, nil)
 	entity := newEntity[T]()
 
 	jsonData, err := json.Marshal(mask)
@@ -183,67 +469,129 @@ func (r *GormRepository[T]) BulkUpdate(ctx context.Context, where Option, mask m
 	if err != nil {
 		return err
 	}
+	updateMap = r.stampActorValues(ctx, db, updateMap)
+	updateMap = r.touchUpdate(db, nil, updateMap)
 
 	return db.Model(&entity).Omit(clause.Associations).Where(where(db)).Updates(updateMap).Error
 }
 
 func (r *GormRepository[T]) UpdateByIdWithMap(ctx context.Context, id uuid.UUID, values map[string]interface{}, options ...Option) (*T, error) {
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	var result *T
+	err := r.intercept(ctx, "UpdateByIdWithMap", false, func(ctx context.Context) (err error) {
+		result, err = r.updateByIdWithMap(ctx, id, values, options...)
+		return err
+	})
+	return result, err
+}
+
+func (r *GormRepository[T]) updateByIdWithMap(ctx context.Context, id uuid.UUID, values map[string]interface{}, options ...Option) (*T, error) {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return nil, err
+	}
+	db = r.routeTable(ctx, db, nil)
 	entity := newEntity[T]()
 
+	if len(r.hooks[HookBeforeUpdate]) > 0 {
+		if err := r.runHooks(ctx, HookBeforeUpdate, entityWithId[T](id)); err != nil {
+			return nil, err
+		}
+	}
+
+	values = r.stampActorValues(ctx, db, values)
+	values = r.touchUpdate(db, nil, values)
+
 	if err := db.Model(&entity).Omit(clause.Associations).Clauses(clause.Returning{}).Where("id = ?", id).Updates(values).Error; err != nil {
 		return nil, err
 	}
+
+	if err := r.runHooks(ctx, HookAfterUpdate, &entity); err != nil {
+		return nil, err
+	}
 	return &entity, nil
 }
 
 func (r *GormRepository[T]) UpdateByIdWithMask(ctx context.Context, id uuid.UUID, mask map[string]interface{}, entity *T, options ...Option) error {
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	return r.intercept(ctx, "UpdateByIdWithMask", false, func(ctx context.Context) error {
+		return r.updateByIdWithMask(ctx, id, mask, entity, options...)
+	})
+}
+
+func (r *GormRepository[T]) updateByIdWithMask(ctx context.Context, id uuid.UUID, mask map[string]interface{}, entity *T, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return err
+	}
+
+	if err := r.beforeWrite(ctx, HookBeforeUpdate, entity); err != nil {
+		return err
+	}
+	db = r.routeTable(ctx, db, entity)
 
 	updateMap, err := utils.EntityToMap(mask, entity)
 	if err != nil {
 		return err
 	}
+	updateMap = r.stampActorValues(ctx, db, updateMap)
+	updateMap = r.touchUpdate(db, entity, updateMap)
 
-	return db.Model(entity).Omit(clause.Associations).Clauses(clause.Returning{}).Where("id = ?", id).Updates(updateMap).Error
+	if err := db.Model(entity).Omit(clause.Associations).Clauses(clause.Returning{}).Where("id = ?", id).Updates(updateMap).Error; err != nil {
+		return err
+	}
+
+	return r.runHooks(ctx, HookAfterUpdate, entity)
 }
 
 // getCloneForDiff attempts to get an existing clone from transaction context,
-// falling back to a blank entity if no clone is available
-func getCloneForDiff[T any](db *gorm.DB, entity *T) *T {
+// falling back to a blank entity if no clone is available.
+// The returned bool reports whether the clone is a snapshot from the transaction.
+func getCloneForDiff[T any](db *gorm.DB, entity *T) (*T, bool) {
+	if clone, found := findCloneForDiff(db, entity); found {
+		return clone, true
+	}
+	entityBlank := newEntity[T]()
+	return &entityBlank, false
+}
+
+// findCloneForDiff returns the snapshot of entity stored in the transaction context, if any
+func findCloneForDiff[T any](db *gorm.DB, entity *T) (*T, bool) {
 	// Try to get transaction context
 	txInterface, exists := db.Get(txContextKey)
 	if !exists {
-		entityBlank := newEntity[T]()
-		return &entityBlank
+		return nil, false
 	}
 
 	tx, ok := txInterface.(*Tx)
 	if !ok {
-		entityBlank := newEntity[T]()
-		return &entityBlank
+		return nil, false
 	}
 
 	// Try to get cloned entity from transaction
-	entityKey := generateEntityKey(entity)
+	entityKey := generateEntityKey(db, entity)
 	cloneInterface, found := tx.getClonedEntity(entityKey)
 	if !found {
-		entityBlank := newEntity[T]()
-		return &entityBlank
+		return nil, false
 	}
 
 	// The stored clone should already be a pointer *T
 	clone, ok := cloneInterface.(*T)
-	if !ok {
-		entityBlank := newEntity[T]()
-		return &entityBlank
-	}
-
-	return clone
+	return clone, ok
 }
 
 func (r *GormRepository[T]) UpdateById(ctx context.Context, id uuid.UUID, entity *T, options ...Option) error {
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	return r.intercept(ctx, "UpdateById", false, func(ctx context.Context) error {
+		return r.updateById(ctx, id, entity, options...)
+	})
+}
+
+func (r *GormRepository[T]) updateById(ctx context.Context, id uuid.UUID, entity *T, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return err
+	}
 
 	// Generate diff
 	diffable, ok := any(entity).(Diffable[T])
@@ -251,21 +599,55 @@ func (r *GormRepository[T]) UpdateById(ctx context.Context, id uuid.UUID, entity
 		return fmt.Errorf("entity must implement Diffable[T] interface")
 	}
 
-	clone := getCloneForDiff(db, entity)
+	if err := r.beforeWrite(ctx, HookBeforeUpdate, entity); err != nil {
+		return err
+	}
+	db = r.routeTable(ctx, db, entity)
+
+	clone, isSnapshot := getCloneForDiff(db, entity)
 
 	diff := diffable.Diff(clone)
 	if len(diff) == 0 {
 		return nil // No changes
 	}
 
+	diff = r.touchUpdate(db, entity, diff)
+
+	var snapshot *T
+	if isSnapshot {
+		snapshot = clone
+	}
+
+	c := r.trackChange(AuditOperationUpdate, id.String(), entity, snapshot, diff)
+
 	// Process the diff to handle flattened JSONB paths (dot notation)
 	processedDiff := processJSONBDiff(db, entity, diff)
 
-	return db.Model(entity).Omit(clause.Associations).Clauses(clause.Returning{}).Where("id = ?", id).Updates(processedDiff).Error
+	err := r.writeTracked(ctx, db, c, func(db *gorm.DB) error {
+		return db.Model(entity).Omit(clause.Associations).Clauses(clause.Returning{}).Where("id = ?", id).Updates(processedDiff).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// The written state is the baseline of later updates in the transaction
+	refreshCloneIfInTransaction(db, entity)
+
+	return r.runHooks(ctx, HookAfterUpdate, entity)
 }
 
 func (r *GormRepository[T]) UpdateByIdInPlace(ctx context.Context, id uuid.UUID, entity *T, updateFunc func(), options ...Option) error {
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	return r.intercept(ctx, "UpdateByIdInPlace", false, func(ctx context.Context) error {
+		return r.updateByIdInPlace(ctx, id, entity, updateFunc, options...)
+	})
+}
+
+func (r *GormRepository[T]) updateByIdInPlace(ctx context.Context, id uuid.UUID, entity *T, updateFunc func(), options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return err
+	}
 
 	diffable, isDiffable := any(entity).(Diffable[T])
 	if !isDiffable {
@@ -278,6 +660,11 @@ func (r *GormRepository[T]) UpdateByIdInPlace(ctx context.Context, id uuid.UUID,
 	// Apply the update function to modify the entity in place
 	updateFunc()
 
+	if err := r.beforeWrite(ctx, HookBeforeUpdate, entity); err != nil {
+		return err
+	}
+	db = r.routeTable(ctx, db, entity)
+
 	diff := diffable.Diff(originalClone)
 
 	if len(diff) == 0 {
@@ -285,15 +672,39 @@ func (r *GormRepository[T]) UpdateByIdInPlace(ctx context.Context, id uuid.UUID,
 		return nil
 	}
 
+	diff = r.touchUpdate(db, entity, diff)
+
+	c := r.trackChange(AuditOperationUpdate, id.String(), entity, originalClone, diff)
+
 	// Process the diff to handle flattened JSONB paths (dot notation)
 	processedDiff := processJSONBDiff(db, entity, diff)
 
 	// Perform the update using the processed diff and return the updated entity
-	return db.Model(entity).Omit(clause.Associations).Clauses(clause.Returning{}).Where("id = ?", id).Updates(processedDiff).Error
+	err := r.writeTracked(ctx, db, c, func(db *gorm.DB) error {
+		return db.Model(entity).Omit(clause.Associations).Clauses(clause.Returning{}).Where("id = ?", id).Updates(processedDiff).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// The written state is the baseline of later updates in the transaction
+	refreshCloneIfInTransaction(db, entity)
+
+	return r.runHooks(ctx, HookAfterUpdate, entity)
 }
 
 func (r *GormRepository[T]) UpdateInPlace(ctx context.Context, entity *T, updateFunc func(), options ...Option) error {
-	db := applyOptions(r.DB, options).WithContext(ctx)
+	return r.intercept(ctx, "UpdateInPlace", false, func(ctx context.Context) error {
+		return r.updateInPlace(ctx, entity, updateFunc, options...)
+	})
+}
+
+func (r *GormRepository[T]) updateInPlace(ctx context.Context, entity *T, updateFunc func(), options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return err
+	}
 
 	diffable, isDiffable := any(entity).(Diffable[T])
 	if !isDiffable {
@@ -306,6 +717,11 @@ func (r *GormRepository[T]) UpdateInPlace(ctx context.Context, entity *T, update
 	// Apply the update function to modify the entity in place
 	updateFunc()
 
+	if err := r.beforeWrite(ctx, HookBeforeUpdate, entity); err != nil {
+		return err
+	}
+	db = r.routeTable(ctx, db, entity)
+
 	diff := diffable.Diff(originalClone)
 
 	if len(diff) == 0 {
@@ -313,42 +729,133 @@ func (r *GormRepository[T]) UpdateInPlace(ctx context.Context, entity *T, update
 		return nil
 	}
 
+	diff = r.touchUpdate(db, entity, diff)
+
+	c := r.trackChange(AuditOperationUpdate, primaryKeyString(db, entity), entity, originalClone, diff)
+
 	// Process the diff to handle flattened JSONB paths (dot notation)
 	processedDiff := processJSONBDiff(db, entity, diff)
 
 	// Perform the update using the processed diff - GORM will extract the primary key from the entity
-	return db.Model(entity).Omit(clause.Associations).Clauses(clause.Returning{}).Updates(processedDiff).Error
+	err := r.writeTracked(ctx, db, c, func(db *gorm.DB) error {
+		return db.Model(entity).Omit(clause.Associations).Clauses(clause.Returning{}).Updates(processedDiff).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// The written state is the baseline of later updates in the transaction
+	refreshCloneIfInTransaction(db, entity)
+
+	return r.runHooks(ctx, HookAfterUpdate, entity)
 }
 
 func (r *GormRepository[T]) DeleteById(ctx context.Context, id uuid.UUID, options ...Option) error {
-	db := applyOptions(r.DB, options).WithContext(ctx)
-	return db.Delete(new(T), "id = ?", id).Error
+	return r.intercept(ctx, "DeleteById", false, func(ctx context.Context) error {
+		return r.deleteById(ctx, id, options...)
+	})
+}
+
+func (r *GormRepository[T]) deleteById(ctx context.Context, id uuid.UUID, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+	if err := readOnlyViolation(db); err != nil {
+		return err
+	}
+	db = r.routeTable(ctx, db, nil)
+
+	var entity *T
+	if len(r.hooks[HookBeforeDelete]) > 0 || len(r.hooks[HookAfterDelete]) > 0 {
+		entity = entityWithId[T](id)
+	}
+
+	if err := r.runHooks(ctx, HookBeforeDelete, entity); err != nil {
+		return err
+	}
+
+	c := r.trackChange(AuditOperationDelete, id.String(), nil, nil, nil)
+	err := r.writeTracked(ctx, db, c, func(db *gorm.DB) error {
+		return db.Delete(new(T), "id = ?", id).Error
+	})
+	if err != nil {
+		return err
+	}
+	r.unmapEntity(db, id)
+
+	return r.runHooks(ctx, HookAfterDelete, entity)
 }
 
 func (r *GormRepository[T]) AppendAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
-	return applyOptions(r.DB, options).
-		WithContext(ctx).
-		Model(entity).
-		Omit(association + ".*"). // https://gorm.io/docs/associations.html#Using-Omit-to-Exclude-Fields-or-Associations
-		Association(association).
-		Append(values)
+	return r.intercept(ctx, "AppendAssociation", false, func(ctx context.Context) error {
+		return r.appendAssociation(ctx, entity, association, values, options...)
+	})
+}
+
+func (r *GormRepository[T]) appendAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+
+	return r.writeAssociation(db, entity, association, values, func(db *gorm.DB) error {
+		return db.
+			Model(entity).
+			Omit(association + ".*"). // https://gorm.io/docs/associations.html#Using-Omit-to-Exclude-Fields-or-Associations
+			Association(association).
+			Append(values)
+	})
 }
 
 func (r *GormRepository[T]) RemoveAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
-	return applyOptions(r.DB, options).
-		WithContext(ctx).
+	return r.intercept(ctx, "RemoveAssociation", false, func(ctx context.Context) error {
+		return r.removeAssociation(ctx, entity, association, values, options...)
+	})
+}
+
+func (r *GormRepository[T]) removeAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+
+	return db.
 		Model(entity).
 		Association(association).
 		Delete(values)
 }
 
 func (r *GormRepository[T]) ReplaceAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
-	return applyOptions(r.DB, options).
-		WithContext(ctx).
+	return r.intercept(ctx, "ReplaceAssociation", false, func(ctx context.Context) error {
+		return r.replaceAssociation(ctx, entity, association, values, options...)
+	})
+}
+
+func (r *GormRepository[T]) replaceAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+
+	return r.writeAssociation(db, entity, association, values, func(db *gorm.DB) error {
+		return db.
+			Model(entity).
+			Omit(association + ".*").
+			Association(association).
+			Replace(values)
+	})
+}
+
+// ClearAssociation removes all the associated entities of entity, without listing them first.
+// Only the references are removed: join rows of many2many associations, foreign keys of has
+// one and has many associations, which must be nullable.
+func (r *GormRepository[T]) ClearAssociation(ctx context.Context, entity *T, association string, options ...Option) error {
+	return r.intercept(ctx, "ClearAssociation", false, func(ctx context.Context) error {
+		return r.clearAssociation(ctx, entity, association, options...)
+	})
+}
+
+func (r *GormRepository[T]) clearAssociation(ctx context.Context, entity *T, association string, options ...Option) error {
+	db, cancel := r.session(ctx, options)
+	defer cancel()
+
+	return db.
 		Model(entity).
-		Omit(association + ".*").
 		Association(association).
-		Replace(values)
+		Clear()
 }
 
 func (r *GormRepository[T]) GetDB() *gorm.DB {
@@ -357,13 +864,87 @@ func (r *GormRepository[T]) GetDB() *gorm.DB {
 
 // BeginTransaction starts a new transaction that should be used with defer for automatic cleanup
 func (r *GormRepository[T]) BeginTransaction() *Tx {
-	gtx := r.DB.Begin()
-	return &Tx{
+	return r.BeginTransactionWithContext(context.Background())
+}
+
+// BeginTransactionWithContext starts a new transaction bound to ctx and runs the TxBeginHooks
+// of the repository in it. When a hook fails the transaction is rolled back and the error is
+// returned by tx.Error().
+func (r *GormRepository[T]) BeginTransactionWithContext(ctx context.Context) *Tx {
+	return r.beginTransaction(ctx, nil)
+}
+
+// BeginTransactionWithOptions is BeginTransactionWithContext with an isolation level or
+// read-only mode, e.g. sql.TxOptions{Isolation: sql.LevelSerializable} for diff based
+// workflows that must not interleave with concurrent updates
+func (r *GormRepository[T]) BeginTransactionWithOptions(ctx context.Context, opts sql.TxOptions) *Tx {
+	return r.beginTransaction(ctx, &opts)
+}
+
+// BeginReadOnlyTransaction starts a read-only transaction, for reads that need a consistent
+// view of several tables such as reports. Repository writes within it fail with
+// ErrReadOnlyTransaction.
+func (r *GormRepository[T]) BeginReadOnlyTransaction(ctx context.Context) *Tx {
+	return r.beginTransaction(ctx, &sql.TxOptions{ReadOnly: true})
+}
+
+// RunInReadOnlyTransaction runs fn within a new read-only transaction
+func (r *GormRepository[T]) RunInReadOnlyTransaction(ctx context.Context, fn func(tx *Tx) error) error {
+	return r.runInTransaction(ctx, &sql.TxOptions{ReadOnly: true}, fn)
+}
+
+// beginTransaction starts a transaction with opts, nil for the driver defaults
+func (r *GormRepository[T]) beginTransaction(ctx context.Context, opts *sql.TxOptions) *Tx {
+	var gtx *gorm.DB
+	if opts != nil {
+		gtx = r.DB.WithContext(ctx).Begin(opts)
+	} else {
+		gtx = r.DB.WithContext(ctx).Begin()
+	}
+
+	tx := &Tx{
 		gtx:            gtx,
 		committed:      false,
 		rolledBack:     false,
-		clonedEntities: make(map[string]interface{}),
+		clonedEntities: newSnapshotStore(r.config.snapshotLimit),
+		ctx:            ctx,
+		startedAt:      time.Now(),
+		endHooks:       r.config.txEndHooks,
+		logger:         r.config.logger,
+		readOnly:       opts != nil && opts.ReadOnly,
+	}
+
+	if gtx.Error == nil {
+		if err := r.runTxBeginHooks(ctx, gtx); err != nil {
+			gtx.Rollback()
+			gtx.AddError(err)
+			tx.rolledBack = true
+		}
+	}
+
+	return tx
+}
+
+// RunInTransaction runs fn within a new transaction, committing it when fn returns nil
+// and rolling it back otherwise
+func (r *GormRepository[T]) RunInTransaction(ctx context.Context, fn func(tx *Tx) error) error {
+	return r.runInTransaction(ctx, nil, fn)
+}
+
+// RunInTransactionWithOptions is RunInTransaction with an isolation level or read-only mode
+func (r *GormRepository[T]) RunInTransactionWithOptions(ctx context.Context, opts sql.TxOptions, fn func(tx *Tx) error) error {
+	return r.runInTransaction(ctx, &opts, fn)
+}
+
+// runInTransaction runs fn within a new transaction started with opts
+func (r *GormRepository[T]) runInTransaction(ctx context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) (err error) {
+	tx := r.beginTransaction(ctx, opts)
+	if err := tx.Error(); err != nil {
+		return err
 	}
+	defer tx.Finish(&err)
+
+	return fn(tx)
 }
 
 // WithTx returns an option to run the query within a transaction.
@@ -394,43 +975,178 @@ type Tx struct {
 	rolledBack bool
 	// clonedEntities stores cloned entities as snapshots during transaction
 	// key is a unique identifier for the entity, value is the cloned entity snapshot
-	clonedEntities map[string]interface{}
+	clonedEntities *snapshotStore
 	mutex          sync.RWMutex
+	// afterCommit holds the callbacks registered with OnCommit
+	afterCommit []func()
+	// afterRollback holds the callbacks registered with OnRollback
+	afterRollback []func(cause error)
+	// ctx, startedAt and endHooks describe the transaction for its TxEndHooks
+	ctx       context.Context
+	startedAt time.Time
+	endHooks  []TxEndHook
+	logger    Logger
+	// parent and savepoint are set on nested transactions, which run within a savepoint
+	// of their parent
+	parent     *Tx
+	savepoint  string
+	savepoints int
+	// readOnly is set on transactions started with sql.TxOptions{ReadOnly: true}
+	readOnly bool
+	// identities holds the entities returned by FindById with WithIdentityMap, by type and id
+	identities map[string]interface{}
 }
 
-// BeginTransaction starts a nested transaction
+// BeginTransaction starts a nested transaction: a savepoint within tx. The nested transaction
+// starts with the snapshots of tx; committing it releases the savepoint and hands its snapshots
+// and OnCommit callbacks to tx, rolling it back only undoes the changes made since it began.
 func (tx *Tx) BeginTransaction() *Tx {
-	gtx := tx.gtx.Begin()
-	return &Tx{
-		gtx:            gtx,
+	tx.mutex.Lock()
+	tx.savepoints++
+	savepoint := fmt.Sprintf("sp_%d_%d", tx.depth()+1, tx.savepoints)
+	clonedEntities := tx.clonedEntities.copy()
+	identities := make(map[string]interface{}, len(tx.identities))
+	for key, entity := range tx.identities {
+		identities[key] = entity
+	}
+	tx.mutex.Unlock()
+
+	nested := &Tx{
+		gtx:            tx.gtx,
 		committed:      false,
 		rolledBack:     false,
-		clonedEntities: make(map[string]interface{}),
+		clonedEntities: clonedEntities,
+		ctx:            tx.ctx,
+		logger:         tx.logger,
+		parent:         tx,
+		savepoint:      savepoint,
+		readOnly:       tx.readOnly,
+		identities:     identities,
 	}
+
+	if err := tx.gtx.SavePoint(savepoint).Error; err != nil {
+		nested.gtx = tx.gtx.Session(&gorm.Session{})
+		nested.gtx.AddError(err)
+		nested.rolledBack = true
+	}
+
+	return nested
+}
+
+// depth returns the number of transactions tx is nested in
+func (tx *Tx) depth() int {
+	depth := 0
+	for parent := tx.parent; parent != nil; parent = parent.parent {
+		depth++
+	}
+	return depth
 }
 
-// Commit commits the transaction
+// Commit commits the transaction. Nested transactions release their savepoint instead,
+// their changes being committed with the outermost transaction.
 func (tx *Tx) Commit() error {
 	if tx.committed || tx.rolledBack {
 		return nil
 	}
 
+	if tx.parent != nil {
+		return tx.release()
+	}
+
 	err := tx.gtx.Commit().Error
 	if err == nil {
 		tx.committed = true
+		tx.runAfterCommit()
+		tx.runEndHooks(true)
 	}
 	return err
 }
 
-// Rollback rolls back the transaction
+// release releases the savepoint of a nested transaction and hands its snapshots and OnCommit
+// callbacks to the parent
+func (tx *Tx) release() error {
+	if err := tx.gtx.Exec("RELEASE SAVEPOINT " + tx.savepoint).Error; err != nil {
+		return err
+	}
+	tx.committed = true
+
+	tx.mutex.Lock()
+	clonedEntities, callbacks, rollbackCallbacks := tx.clonedEntities, tx.afterCommit, tx.afterRollback
+	identities := tx.identities
+	tx.afterCommit = nil
+	tx.afterRollback = nil
+	tx.mutex.Unlock()
+
+	tx.parent.mutex.Lock()
+	tx.parent.clonedEntities.merge(clonedEntities)
+	// The nested transaction started with the identities of its parent, including deletions
+	tx.parent.identities = identities
+	tx.parent.afterCommit = append(tx.parent.afterCommit, callbacks...)
+	tx.parent.afterRollback = append(tx.parent.afterRollback, rollbackCallbacks...)
+	tx.parent.mutex.Unlock()
+
+	return nil
+}
+
+// OnCommit registers fn to run after the transaction commits successfully.
+// Callbacks are discarded when the transaction rolls back.
+func (tx *Tx) OnCommit(fn func()) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.afterCommit = append(tx.afterCommit, fn)
+}
+
+// OnRollback registers fn to run after the transaction rolls back, typically to compensate
+// side effects made outside of the database. fn receives the error that caused the rollback
+// when the transaction is ended by Finish, nil after an explicit Rollback.
+// Callbacks are discarded when the transaction commits.
+func (tx *Tx) OnRollback(fn func(cause error)) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.afterRollback = append(tx.afterRollback, fn)
+}
+
+// runAfterCommit runs the OnCommit callbacks in registration order
+func (tx *Tx) runAfterCommit() {
+	tx.mutex.Lock()
+	callbacks := tx.afterCommit
+	tx.afterCommit = nil
+	tx.afterRollback = nil
+	tx.mutex.Unlock()
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// Rollback rolls back the transaction. Nested transactions roll back to their savepoint.
 func (tx *Tx) Rollback() error {
+	return tx.rollback(nil)
+}
+
+// rollback rolls back the transaction and runs its OnRollback callbacks with cause
+func (tx *Tx) rollback(cause error) error {
 	if tx.committed || tx.rolledBack {
 		return nil
 	}
 
-	err := tx.gtx.Rollback().Error
+	var err error
+	if tx.parent != nil {
+		err = tx.gtx.RollbackTo(tx.savepoint).Error
+	} else {
+		err = tx.gtx.Rollback().Error
+	}
 	if err == nil {
 		tx.rolledBack = true
+		tx.mutex.Lock()
+		tx.afterCommit = nil
+		callbacks := tx.afterRollback
+		tx.afterRollback = nil
+		tx.mutex.Unlock()
+		for _, fn := range callbacks {
+			fn(cause)
+		}
+		tx.runEndHooks(false)
 	}
 	return err
 }
@@ -439,16 +1155,27 @@ func (tx *Tx) Rollback() error {
 // Usage: defer tx.Finish(&err)
 // Use this for simple cases where you don't need complex error handling
 // Will commit if err is nil, rollback if err is set
+// A panic unwinding through the deferred Finish rolls the transaction back and is re-raised,
+// so the transaction and its connection aren't leaked.
 func (tx *Tx) Finish(err *error) {
+	if recovered := recover(); recovered != nil {
+		if !tx.committed && !tx.rolledBack {
+			if rollbackErr := tx.rollback(fmt.Errorf("panic: %v", recovered)); rollbackErr != nil {
+				tx.log(LogLevelError, "transaction rollback failed", Field{Key: "error", Value: rollbackErr}, Field{Key: "panic", Value: recovered})
+			}
+		}
+		panic(recovered)
+	}
+
 	if tx.committed || tx.rolledBack {
 		return
 	}
 
 	if *err != nil {
 		// If there was an error, rollback
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+		if rollbackErr := tx.rollback(*err); rollbackErr != nil {
 			// Log rollback error but don't override the original error
-			// You might want to use your logging framework here
+			tx.log(LogLevelError, "transaction rollback failed", Field{Key: "error", Value: rollbackErr}, Field{Key: "cause", Value: *err})
 		}
 	} else {
 		// If no error, commit
@@ -458,6 +1185,11 @@ func (tx *Tx) Finish(err *error) {
 	}
 }
 
+// ReadOnly reports whether the transaction was started read-only
+func (tx *Tx) ReadOnly() bool {
+	return tx.readOnly
+}
+
 // Error returns any error from the underlying GORM transaction
 func (tx *Tx) Error() error {
 	return tx.gtx.Error
@@ -467,37 +1199,54 @@ func (tx *Tx) Error() error {
 func (tx *Tx) storeClonedEntity(entityKey string, original interface{}) {
 	tx.mutex.Lock()
 	defer tx.mutex.Unlock()
-	tx.clonedEntities[entityKey] = original
+	tx.clonedEntities.put(entityKey, original)
 }
 
-// getClonedEntity retrieves the original entity if it was cloned
+// getClonedEntity retrieves the original entity if it was cloned, marking it as recently used
 func (tx *Tx) getClonedEntity(entityKey string) (interface{}, bool) {
-	tx.mutex.RLock()
-	defer tx.mutex.RUnlock()
-	original, exists := tx.clonedEntities[entityKey]
-	return original, exists
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	return tx.clonedEntities.get(entityKey)
+}
+
+// removeClonedEntity drops the snapshot of an entity
+func (tx *Tx) removeClonedEntity(entityKey string) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.clonedEntities.remove(entityKey)
 }
 
-// generateEntityKey creates a unique key for an entity based on its type and Id
-func generateEntityKey(entity interface{}) string {
+// generateEntityKey creates a unique key for an entity based on its type, table and primary
+// key, as parsed by GORM, so Id, ID, custom and composite primary keys are all supported
+func generateEntityKey(db *gorm.DB, entity interface{}) string {
 	entityType := reflect.TypeOf(entity)
 	if entityType.Kind() == reflect.Ptr {
 		entityType = entityType.Elem()
 	}
 
-	// Try to get Id field using reflection
-	entityValue := reflect.ValueOf(entity)
-	if entityValue.Kind() == reflect.Ptr {
-		entityValue = entityValue.Elem()
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil || len(stmt.Schema.PrimaryFields) == 0 {
+		// Fallback to memory address if no primary key
+		return fmt.Sprintf("%s_%p", entityKeyPrefix(db, entityType.Name()), entity)
 	}
 
-	idField := entityValue.FieldByName("Id")
-	if !idField.IsValid() {
-		// Fallback to memory address if no Id field
-		return fmt.Sprintf("%s_%p", entityType.Name(), entity)
+	entityValue := reflect.ValueOf(entity)
+	values := make([]string, len(stmt.Schema.PrimaryFields))
+	for i, field := range stmt.Schema.PrimaryFields {
+		value, _ := field.ValueOf(context.Background(), entityValue)
+		values[i] = fmt.Sprint(value)
 	}
 
-	return fmt.Sprintf("%s_%v", entityType.Name(), idField.Interface())
+	return fmt.Sprintf("%s_%s", entityKeyPrefix(db, entityType.Name()), strings.Join(values, "_"))
+}
+
+// entityKeyPrefix returns the entity type name, followed by the table of db when overridden by
+// WithTable or a TableRouter: rows of tables sharing a structure may share their primary key
+func entityKeyPrefix(db *gorm.DB, typeName string) string {
+	if db.Statement.Table != "" {
+		return typeName + "@" + db.Statement.Table
+	}
+	return typeName
 }
 
 // storeCloneIfInTransaction stores a clone of the entity if we're in a transaction and the entity supports cloning
@@ -519,12 +1268,30 @@ func storeCloneIfInTransaction[T any](db *gorm.DB, entity *T) {
 		return
 	}
 
+	if noSnapshot, _ := db.Get(noSnapshotContextKey); noSnapshot == true {
+		return
+	}
+
 	// Store the cloned entity as a snapshot
-	entityKey := generateEntityKey(entity)
+	entityKey := generateEntityKey(db, entity)
 	clone := cloneable.Clone()
 	tx.storeClonedEntity(entityKey, clone)
 }
 
+// refreshCloneIfInTransaction replaces the snapshot of an entity written within a transaction.
+// With WithNoSnapshot the previous snapshot is dropped instead, being outdated.
+func refreshCloneIfInTransaction[T any](db *gorm.DB, entity *T) {
+	if noSnapshot, _ := db.Get(noSnapshotContextKey); noSnapshot == true {
+		if txInterface, inTx := db.Get(txContextKey); inTx {
+			if tx, ok := txInterface.(*Tx); ok {
+				tx.removeClonedEntity(generateEntityKey(db, entity))
+			}
+		}
+		return
+	}
+	storeCloneIfInTransaction(db, entity)
+}
+
 // getJSONColumnType detects if a column is 'json' or 'jsonb' type in PostgreSQL
 // Returns "jsonb" for jsonb columns, "json" for json columns, or empty string if unable to determine
 // Uses a cache to avoid repeated database queries for the same table.column combinations
@@ -563,30 +1330,12 @@ func getJSONColumnType(db *gorm.DB, tableName string, columnName string) string
 // processJSONBDiff processes a diff map and converts flattened JSONB paths (dot notation)
 // into jsonb_set expressions for PostgreSQL
 func processJSONBDiff(db *gorm.DB, model interface{}, diff map[string]interface{}) map[string]interface{} {
-	result := make(map[string]interface{})
-	grouped := make(map[string]map[string]interface{})
-
 	// Get schema from the model
 	stmt := &gorm.Statement{DB: db}
 	stmt.Parse(model)
 
-	// Group flattened paths by their root field name
-	for key, value := range diff {
-		if strings.Contains(key, ".") {
-			// This is a flattened JSONB path like "status.mode" or "status.state.code"
-			parts := strings.SplitN(key, ".", 2)
-			fieldName := parts[0] // e.g., "status"
-			subPath := parts[1]   // e.g., "mode" or "state.code"
-
-			if grouped[fieldName] == nil {
-				grouped[fieldName] = make(map[string]interface{})
-			}
-			grouped[fieldName][subPath] = value
-		} else {
-			// Regular field (not a flattened JSONB path)
-			result[key] = value
-		}
-	}
+	// Group flattened paths like "status.mode" or "status.state.code" by their root field name
+	result, grouped := utils.GroupDiff(diff)
 
 	// Convert grouped paths into jsonb_set expressions
 	for fieldName, paths := range grouped {
@@ -632,11 +1381,18 @@ func buildJSONBSetExpression(db *gorm.DB, schema *schema.Schema, fieldName strin
 		// Fallback: use the field name as-is
 		columnName = fieldName
 	}
-	columnType := getJSONColumnType(db, schema.Table, columnName)
+	tableName := schema.Table
+	if db.Statement.Table != "" {
+		// Overridden with WithTable
+		tableName = db.Statement.Table
+	}
+	columnType := getJSONColumnType(db, tableName, columnName)
 
 	// Start with the original column value (or empty object if NULL)
-	expr := fmt.Sprintf("COALESCE(?::%s, '{}'::jsonb)", columnType)
-	args := []interface{}{clause.Column{Name: columnName}}
+	column := clause.Column{Name: columnName}
+	original := fmt.Sprintf("COALESCE(?::%s, '{}'::jsonb)", columnType)
+	expr := original
+	args := []interface{}{column}
 
 	// Sort paths for consistent ordering
 	sortedPaths := make([]string, 0, len(paths))
@@ -649,11 +1405,18 @@ func buildJSONBSetExpression(db *gorm.DB, schema *schema.Schema, fieldName strin
 	for _, path := range sortedPaths {
 		value := paths[path]
 
-		// Convert "mode" or "state.code" to PostgreSQL array format
-		// "mode" -> {mode}
-		// "state.code" -> {state,code}
+		// Convert "mode" or "state.code" to a PostgreSQL text array bound as a parameter
+		// "mode" -> {"mode"}
+		// "state.code" -> {"state","code"}
 		pathParts := strings.Split(path, ".")
-		pathArray := "{" + strings.Join(pathParts, ",") + "}"
+		pathArray := buildJSONBPathArray(pathParts)
+
+		if value == utils.Removed {
+			// Delete the key (e.g. a map entry) from the document
+			expr = fmt.Sprintf("(%s #- ?::text[])", expr)
+			args = append(args, pathArray)
+			continue
+		}
 
 		// Serialize value to JSON
 		valueJSON, err := json.Marshal(value)
@@ -662,14 +1425,49 @@ func buildJSONBSetExpression(db *gorm.DB, schema *schema.Schema, fieldName strin
 			continue
 		}
 
+		if pathParts[len(pathParts)-1] == utils.SliceAppendSegment {
+			// "items.-" holds elements appended to the array at "items"
+			parentParts := pathParts[:len(pathParts)-1]
+			if len(parentParts) == 0 {
+				// The column itself is the array, read as an empty array rather than an
+				// empty object when NULL, which || would keep as a first element
+				if expr == original {
+					expr = fmt.Sprintf("COALESCE(?::%s, '[]'::jsonb)", columnType)
+				}
+				expr = fmt.Sprintf("(%s || ?::jsonb)", expr)
+				args = append(args, string(valueJSON))
+				continue
+			}
+
+			// The array is read from the original column rather than from the current
+			// expression, which would double the SQL with every append. No earlier path
+			// changed it: "-" sorts before the element indexes of the same array.
+			parentArray := buildJSONBPathArray(parentParts)
+			expr = fmt.Sprintf("jsonb_set(%s, ?::text[], COALESCE(%s #> ?::text[], '[]'::jsonb) || ?::jsonb)", expr, original)
+			args = append(args, parentArray, column, parentArray, string(valueJSON))
+			continue
+		}
+
 		// Nest another jsonb_set call
-		expr = fmt.Sprintf("jsonb_set(%s, '%s', ?::jsonb)", expr, pathArray)
-		args = append(args, string(valueJSON))
+		expr = fmt.Sprintf("jsonb_set(%s, ?::text[], ?::jsonb)", expr)
+		args = append(args, pathArray, string(valueJSON))
 	}
 
 	return gorm.Expr(expr, args...)
 }
 
+// buildJSONBPathArray renders path segments as a PostgreSQL text array literal.
+// Segments are quoted so map keys containing commas, braces or quotes stay a single element.
+func buildJSONBPathArray(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, `\`, `\\`)
+		part = strings.ReplaceAll(part, `"`, `\"`)
+		quoted[i] = `"` + part + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
 // getTableNameFromDB extracts the table name from the GORM DB statement
 func getTableNameFromDB(db *gorm.DB) string {
 	if db.Statement != nil && db.Statement.Table != "" {
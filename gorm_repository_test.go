@@ -5,16 +5,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils"
 	"github.com/ikateclab/gorm-repository/utils/tests"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -27,6 +30,7 @@ func truncateAllTables(db *gorm.DB) error {
 		"test_posts",
 		"test_tags",
 		"test_simple_entities",
+		"audit_entries",
 	}
 	for _, table := range tables {
 		if err := db.Exec(fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE", table)).Error; err != nil {
@@ -527,6 +531,65 @@ func TestGormRepository_Transaction_Finish_Error(t *testing.T) {
 	// The actual rollback happens in defer
 }
 
+func TestGormRepository_Transaction_Finish_Panic(t *testing.T) {
+	db := setupTestDB(t)
+	repo := &GormRepository[tests.TestUser]{DB: db}
+	ctx := context.Background()
+
+	tx := repo.BeginTransaction()
+	require.PanicsWithValue(t, "boom", func() {
+		var err error
+		defer tx.Finish(&err)
+
+		err = repo.Create(ctx, createTestUser(), WithTx(tx))
+		require.NoError(t, err, "Create in transaction should not fail")
+		panic("boom")
+	}, "Expected Finish to re-panic")
+
+	require.True(t, tx.rolledBack, "Expected the panic to roll the transaction back")
+
+	var count int64
+	db.Model(&tests.TestUser{}).Count(&count)
+	require.Equal(t, int64(0), count, "Expected 0 users after rollback")
+}
+
+func TestTx_OnRollback(t *testing.T) {
+	db := setupTestDB(t)
+	repo := &GormRepository[tests.TestUser]{DB: db}
+	ctx := context.Background()
+
+	var causes []error
+	onRollback := func(cause error) { causes = append(causes, cause) }
+
+	err := repo.RunInTransaction(ctx, func(tx *Tx) error {
+		tx.OnRollback(onRollback)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, causes, "Expected OnRollback callbacks to be discarded on commit")
+
+	err = repo.RunInTransaction(ctx, func(tx *Tx) error {
+		tx.OnRollback(onRollback)
+		return gorm.ErrInvalidData
+	})
+	require.ErrorIs(t, err, gorm.ErrInvalidData)
+	require.Equal(t, []error{gorm.ErrInvalidData}, causes, "Expected the cause of the rollback")
+
+	causes = nil
+	tx := repo.BeginTransaction()
+	rolledBack := tx.BeginTransaction()
+	rolledBack.OnRollback(onRollback)
+	require.NoError(t, rolledBack.Rollback())
+	require.Equal(t, []error{nil}, causes, "Expected explicit rollbacks to have no cause")
+
+	released := tx.BeginTransaction()
+	released.OnRollback(onRollback)
+	require.NoError(t, released.Commit())
+	require.Len(t, causes, 1, "Expected released savepoints to wait for the outer transaction")
+	require.NoError(t, tx.Rollback())
+	require.Len(t, causes, 2, "Expected the outer rollback to run the callbacks of released savepoints")
+}
+
 func TestGormRepository_UpdateById_WithoutTransaction(t *testing.T) {
 	db := setupTestDB(t)
 	repo := &GormRepository[tests.TestUser]{DB: db}
@@ -657,6 +720,81 @@ func TestGormRepository_UpdateById_ZeroValue_WithTransaction(t *testing.T) {
 	require.False(t, updatedUser.Data.Married, "Expected updated Data.Married")
 }
 
+func TestGormRepository_UpdateById_AfterFindMany_WithTransaction(t *testing.T) {
+	db := setupTestDB(t)
+	repo := &GormRepository[tests.TestUser]{DB: db}
+	ctx := context.Background()
+
+	user := createTestUser()
+	err := repo.Create(ctx, user)
+	require.NoError(t, err, "Failed to create test user")
+
+	// Start transaction and list users (this creates clones)
+	tx := repo.BeginTransaction()
+
+	users, err := repo.FindMany(ctx, WithTx(tx))
+	require.NoError(t, err, "Failed to find users in transaction")
+	require.Len(t, users, 1)
+
+	page, err := repo.FindPaginated(ctx, 1, 10, WithTx(tx))
+	require.NoError(t, err, "Failed to find paginated users in transaction")
+	_, isSnapshot := getCloneForDiff(WithTx(tx)(db), page.Data[0])
+	require.True(t, isSnapshot, "Expected FindPaginated to store snapshots")
+
+	// Zero values are only written when diffing against a snapshot
+	users[0].Age = 0
+	users[0].Active = false
+
+	err = repo.UpdateById(ctx, user.Id, users[0], WithTx(tx))
+	require.NoError(t, err, "UpdateById with transaction should not fail")
+
+	err = tx.Commit()
+	require.NoError(t, err, "Failed to commit transaction")
+
+	updatedUser, err := repo.FindById(ctx, user.Id)
+	require.NoError(t, err, "Failed to find updated user")
+	require.Equal(t, 0, updatedUser.Age, "Expected updated age")
+	require.False(t, updatedUser.Active, "Expected updated active")
+	require.Equal(t, user.Name, updatedUser.Name, "Expected unchanged name")
+}
+
+func TestGormRepository_UpdateById_RefreshesSnapshot_WithTransaction(t *testing.T) {
+	db := setupTestDB(t)
+	publisher := &recordingPublisher{}
+	repo := NewGormRepository[tests.TestUser](db, WithChangePublisher(publisher))
+	ctx := context.Background()
+
+	user := createTestUser()
+	err := repo.Create(ctx, user)
+	require.NoError(t, err, "Failed to create test user")
+
+	tx := repo.BeginTransaction()
+
+	foundUser, err := repo.FindById(ctx, user.Id, WithTx(tx))
+	require.NoError(t, err, "Failed to find user in transaction")
+
+	foundUser.Name = "First Update"
+	err = repo.UpdateById(ctx, user.Id, foundUser, WithTx(tx))
+	require.NoError(t, err, "First UpdateById should not fail")
+
+	err = repo.UpdateByIdInPlace(ctx, user.Id, foundUser, func() {
+		foundUser.Age = 41
+	}, WithTx(tx))
+	require.NoError(t, err, "UpdateByIdInPlace should not fail")
+
+	foundUser.Active = false
+	err = repo.UpdateById(ctx, user.Id, foundUser, WithTx(tx))
+	require.NoError(t, err, "Second UpdateById should not fail")
+
+	require.NoError(t, tx.Commit(), "Failed to commit transaction")
+
+	events := publisher.published()
+	require.Len(t, events, 4, "Expected create and update events")
+	require.Equal(t, map[string]interface{}{"name": "First Update"}, events[1].Diff)
+	require.Equal(t, map[string]interface{}{"age": 41}, events[2].Diff)
+	require.Equal(t, map[string]interface{}{"active": false}, events[3].Diff, "Expected the second update to diff against the first")
+}
+
 func TestGormRepository_UpdateById_WithTransactionNoClone(t *testing.T) {
 	db := setupTestDB(t)
 	repo := &GormRepository[tests.TestUser]{DB: db}
@@ -988,6 +1126,73 @@ func TestGormRepository_UpdateByIdInPlace_ZeroValue_WithTransaction(t *testing.T
 	require.Equal(t, 0, updatedUser.Age, "Expected age to be updated")
 }
 
+func TestProcessJSONBDiff_SliceAppend(t *testing.T) {
+	db := setupTestDB(t)
+	user := createTestUser()
+
+	processed := processJSONBDiff(db, user, map[string]interface{}{
+		"whatsAppData.labels.-": []string{"vip"},
+	})
+
+	expr, ok := processed["WhatsAppData"].(clause.Expr)
+	require.True(t, ok, "Expected a jsonb expression for WhatsAppData")
+	require.Contains(t, expr.SQL, "jsonb_set(", "Append should set the parent array")
+	require.Contains(t, expr.SQL, "#> ?::text[], '[]'::jsonb) || ?::jsonb", "Append should concatenate to the existing array")
+	require.Len(t, expr.Vars, 5, "Base expression and parent path are referenced twice plus the appended value")
+	require.Equal(t, `{"labels"}`, expr.Vars[1], "Parent path should be bound as a text array")
+	require.Equal(t, `["vip"]`, expr.Vars[4], "Appended elements should be serialized as a JSON array")
+}
+
+func TestProcessJSONBDiff_SliceAppendToNullColumn(t *testing.T) {
+	db := setupTestDB(t)
+	user := createTestUser()
+
+	processed := processJSONBDiff(db, user, map[string]interface{}{
+		"whatsAppData.-": []string{"vip"},
+	})
+
+	expr, ok := processed["WhatsAppData"].(clause.Expr)
+	require.True(t, ok, "Expected a jsonb expression for WhatsAppData")
+	require.Regexp(t, `^\(COALESCE\(\?::\w+, '\[\]'::jsonb\) \|\| \?::jsonb\)$`, expr.SQL, "Expected a NULL array column to be appended to as an empty array")
+	require.Equal(t, []interface{}{clause.Column{Name: "whats_app_data"}, `["vip"]`}, expr.Vars)
+}
+
+func TestProcessJSONBDiff_SeveralSliceAppends(t *testing.T) {
+	db := setupTestDB(t)
+	user := createTestUser()
+
+	processed := processJSONBDiff(db, user, map[string]interface{}{
+		"whatsAppData.labels.-": []string{"vip"},
+		"whatsAppData.notes.-":  []string{"called"},
+		"whatsAppData.tags.-":   []string{"new"},
+		"whatsAppData.tags.0":   "old",
+	})
+
+	expr, ok := processed["WhatsAppData"].(clause.Expr)
+	require.True(t, ok, "Expected a jsonb expression for WhatsAppData")
+	require.Len(t, expr.Vars, 1+3*4+2, "Expected the arguments to grow linearly with the appends")
+	require.Equal(t, 1+3*2, strings.Count(expr.SQL, "COALESCE("), "Expected each append to read the original column once")
+	require.Equal(t, `["new"]`, expr.Vars[12], "Expected the tags append to come before its element update")
+}
+
+func TestProcessJSONBDiff_MapKeys(t *testing.T) {
+	db := setupTestDB(t)
+	user := createTestUser()
+
+	processed := processJSONBDiff(db, user, map[string]interface{}{
+		"data.labels.vip":  true,
+		"data.labels.old":  utils.Removed,
+		`data.labels.a,"b`: 1,
+	})
+
+	expr, ok := processed["Data"].(clause.Expr)
+	require.True(t, ok, "Expected a jsonb expression for Data")
+	require.Contains(t, expr.SQL, "#- ?::text[]", "Removed keys should be deleted from the document")
+	require.Contains(t, expr.Vars, `{"labels","a,\"b"}`, "Keys should be quoted inside the path array")
+	require.Contains(t, expr.Vars, `{"labels","old"}`, "Removed key path should be bound")
+	require.Contains(t, expr.Vars, `{"labels","vip"}`, "Changed key path should be bound")
+}
+
 func TestMain(m *testing.M) {
 	ctx := context.Background()
 
@@ -1039,6 +1244,7 @@ func TestMain(m *testing.M) {
 		&tests.TestPost{},
 		&tests.TestTag{},
 		&tests.TestSimpleEntity{},
+		&AuditEntry{},
 	)
 	if err != nil {
 		log.Fatalf("auto-migrate failed: %v", err)
@@ -0,0 +1,118 @@
+package gormrepository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// hintClauses are the clauses whose statements receive optimizer hints
+var hintClauses = []string{"SELECT", "UPDATE", "DELETE"}
+
+// WithPlannerHint returns an option adding an optimizer hint comment, /*+ hint */, to the query.
+// On Postgres it needs the pg_hint_plan extension, e.g. WithPlannerHint("SeqScan(users)"); on
+// MySQL it takes optimizer hints such as "MAX_EXECUTION_TIME(1000)". Other dialects ignore it.
+// Hints of several options are merged into one comment. Any * and / of the hint is dropped so
+// it can't close the comment.
+func WithPlannerHint(hint string) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(optimizerHint{hint: plannerHint(hint)})
+	}
+}
+
+// WithIndexHint returns an option asking the planner to use indexes of the table of the entity:
+// an IndexScan hint for pg_hint_plan on Postgres, USE INDEX on MySQL. Other dialects ignore it.
+// Meant for targeted plan fixes; the planner usually knows better.
+func WithIndexHint(indexes ...string) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(indexes) == 0 {
+			return db
+		}
+		if db.Dialector.Name() == "mysql" {
+			return db.Clauses(useIndexHint(indexes))
+		}
+		return db.Clauses(optimizerHint{hint: indexScanHint(indexes)})
+	}
+}
+
+// optimizerHint adds a hint to the /*+ */ comment of the statement: before the statement on
+// Postgres, where pg_hint_plan only reads the leading comment, and after the keyword on MySQL
+type optimizerHint struct {
+	hint clause.Expression
+}
+
+func (h optimizerHint) Build(clause.Builder) {}
+
+func (h optimizerHint) ModifyStatement(stmt *gorm.Statement) {
+	dialect := stmt.Dialector.Name()
+	if dialect != "postgres" && dialect != "mysql" {
+		return
+	}
+
+	for _, name := range hintClauses {
+		c := stmt.Clauses[name]
+		if dialect == "postgres" {
+			hints, _ := c.BeforeExpression.(optimizerHints)
+			c.BeforeExpression = append(hints[:len(hints):len(hints)], h.hint)
+		} else {
+			hints, _ := c.AfterNameExpression.(optimizerHints)
+			c.AfterNameExpression = append(hints[:len(hints):len(hints)], h.hint)
+		}
+		stmt.Clauses[name] = c
+	}
+}
+
+// optimizerHints is the hint comment of a statement
+type optimizerHints []clause.Expression
+
+func (h optimizerHints) Build(builder clause.Builder) {
+	builder.WriteString("/*+ ")
+	for i, hint := range h {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		hint.Build(builder)
+	}
+	builder.WriteString(" */")
+}
+
+// plannerHint is a hint given as is
+type plannerHint string
+
+func (h plannerHint) Build(builder clause.Builder) {
+	builder.WriteString(sanitizeSQLComment(string(h)))
+}
+
+// indexScanHint is the pg_hint_plan IndexScan hint of the table of the statement
+type indexScanHint []string
+
+func (h indexScanHint) Build(builder clause.Builder) {
+	table := ""
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		table = stmt.Table
+	}
+	builder.WriteString("IndexScan(")
+	builder.WriteString(sanitizeSQLComment(strings.Join(append([]string{table}, h...), " ")))
+	builder.WriteByte(')')
+}
+
+// useIndexHint is a MySQL USE INDEX hint, written after the table of the FROM clause
+type useIndexHint []string
+
+func (h useIndexHint) Build(builder clause.Builder) {
+	builder.WriteString("USE INDEX (")
+	for i, index := range h {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteQuoted(index)
+	}
+	builder.WriteByte(')')
+}
+
+func (h useIndexHint) ModifyStatement(stmt *gorm.Statement) {
+	c := stmt.Clauses["FROM"]
+	c.AfterExpression = h
+	stmt.Clauses["FROM"] = c
+}
@@ -0,0 +1,70 @@
+package gormrepository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunPostgres returns a Postgres connection generating SQL without running it
+func dryRunPostgres(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestWithPlannerHint(t *testing.T) {
+	db := dryRunPostgres(t)
+
+	query := applyOptions(db.Model(&tests.TestUser{}), []Option{
+		WithIndexHint("idx_test_users_email"),
+		WithPlannerHint("Parallel(test_users 4 hard)"),
+	})
+	stmt := query.Find(&[]tests.TestUser{}).Statement
+	require.NoError(t, stmt.Error)
+	require.Equal(t, `/*+ IndexScan(test_users idx_test_users_email) Parallel(test_users 4 hard) */ SELECT * FROM "test_users"`, stmt.SQL.String())
+
+	stmt = db.Model(&tests.TestUser{}).Find(&[]tests.TestUser{}).Statement
+	require.Equal(t, `SELECT * FROM "test_users"`, stmt.SQL.String(), "Expected hints to be scoped to their query")
+}
+
+func TestWithPlannerHint_ClosingSequence(t *testing.T) {
+	db := dryRunPostgres(t)
+
+	for _, value := range []string{"**//", "x*?/; DROP TABLE t; --"} {
+		for _, option := range []Option{WithPlannerHint(value), WithIndexHint(value)} {
+			stmt := applyOptions(db.Model(&tests.TestUser{}), []Option{option}).Find(&[]tests.TestUser{}).Statement
+			require.NoError(t, stmt.Error)
+			require.Equal(t, 1, strings.Count(stmt.SQL.String(), "*/"), "Expected %q not to close the hint: %s", value, stmt.SQL.String())
+		}
+	}
+}
+
+func TestWithPlannerHint_SQLComments(t *testing.T) {
+	repo := NewGormRepository[tests.TestUser](dryRunPostgres(t), WithSQLComments("billing"))
+
+	db, cancel := repo.session(context.Background(), []Option{WithPlannerHint("SeqScan(test_users)")})
+	defer cancel()
+	stmt := db.Find(&[]tests.TestUser{}).Statement
+	require.Equal(t, `/*+ SeqScan(test_users) */ /* app=billing */ SELECT * FROM "test_users"`, stmt.SQL.String(), "Expected the hint to stay the leading comment")
+}
+
+func TestWithPlannerHint_OtherDialects(t *testing.T) {
+	db := setupTestDB(t)
+	if db.Dialector.Name() == "postgres" {
+		t.Skip("hints are only ignored by other dialects")
+	}
+	repo := NewGormRepository[tests.TestUser](db)
+
+	_, err := repo.FindMany(context.Background(), WithPlannerHint("SeqScan(test_users)"), WithIndexHint("idx"))
+	require.NoError(t, err)
+}
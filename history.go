@@ -0,0 +1,116 @@
+package gormrepository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ikateclab/gorm-repository/utils"
+)
+
+// ChangeHistoryRepository queries the audit trail recorded for entities of type T
+// (see WithAuditRecorder) and rebuilds past states of an entity from it.
+type ChangeHistoryRepository[T any] struct {
+	DB *gorm.DB
+}
+
+// NewChangeHistoryRepository creates a ChangeHistoryRepository reading the AuditEntry table from db
+func NewChangeHistoryRepository[T any](db *gorm.DB) *ChangeHistoryRepository[T] {
+	return &ChangeHistoryRepository[T]{
+		DB: db,
+	}
+}
+
+// Timeline returns the recorded changes of the entity with the given id, oldest first
+func (h *ChangeHistoryRepository[T]) Timeline(ctx context.Context, id uuid.UUID, options ...Option) ([]*AuditEntry, error) {
+	var entries []*AuditEntry
+	db, cancel := withTimeout(ctx, applyOptions(h.DB, options))
+	defer cancel()
+	if err := h.entriesQuery(db, id).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// StateAt reconstructs the entity with the given id as it was at the given time by replaying
+// the recorded diffs in order. Accurate states require the history to start with the create
+// entry, which holds every field. Returns gorm.ErrRecordNotFound when nothing was recorded
+// for the entity up to that time, or when it was deleted by then.
+func (h *ChangeHistoryRepository[T]) StateAt(ctx context.Context, id uuid.UUID, at time.Time, options ...Option) (*T, error) {
+	var entries []*AuditEntry
+	db, cancel := withTimeout(ctx, applyOptions(h.DB, options))
+	defer cancel()
+	recordedUntil := clause.Lte{Column: auditColumn(db, "CreatedAt"), Value: at}
+	if err := h.entriesQuery(db, id).Where(recordedUntil).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	entity := newEntity[T]()
+	deleted := false
+	for _, entry := range entries {
+		if entry.Operation == AuditOperationDelete {
+			entity, deleted = newEntity[T](), true
+			continue
+		}
+
+		deleted = false
+		if err := utils.ApplyDiff(&entity, replayedValues(entry)); err != nil {
+			return nil, fmt.Errorf("replay audit entry %s: %w", entry.Id, err)
+		}
+	}
+
+	if deleted {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &entity, nil
+}
+
+// replayedValues returns the NewValues of entry with its removed paths set back to
+// utils.Removed, so ApplyDiff deletes the map keys instead of setting them to zero values
+func replayedValues(entry *AuditEntry) map[string]interface{} {
+	if len(entry.RemovedPaths) == 0 {
+		return entry.NewValues
+	}
+
+	values := make(map[string]interface{}, len(entry.NewValues))
+	for key, value := range entry.NewValues {
+		values[key] = value
+	}
+	for _, path := range entry.RemovedPaths {
+		values[path] = utils.Removed
+	}
+
+	return values
+}
+
+// entriesQuery selects the audit entries of an entity in the order they were recorded. Entries
+// recorded at the same time, as in one transaction, are ordered by their time-ordered Id.
+func (h *ChangeHistoryRepository[T]) entriesQuery(db *gorm.DB, id uuid.UUID) *gorm.DB {
+	entityType := reflect.TypeOf((*T)(nil)).Elem().Name()
+	return db.Model(&AuditEntry{}).
+		Where(&AuditEntry{EntityType: entityType, EntityId: id.String()}).
+		Order(clause.OrderByColumn{Column: auditColumn(db, "CreatedAt")}).
+		Order(clause.OrderByColumn{Column: auditColumn(db, "Id")})
+}
+
+// auditColumn resolves the column of an AuditEntry field with the naming strategy of db
+func auditColumn(db *gorm.DB, field string) clause.Column {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&AuditEntry{}); err == nil {
+		if schemaField := stmt.Schema.LookUpField(field); schemaField != nil {
+			return clause.Column{Name: schemaField.DBName}
+		}
+	}
+	return clause.Column{Name: field}
+}
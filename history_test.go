@@ -0,0 +1,139 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ikateclab/gorm-repository/utils"
+)
+
+func TestChangeHistoryRepository_TimelineAndStateAt(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db, WithAuditRecorder(GormAuditRecorder{}))
+	history := NewChangeHistoryRepository[tests.TestProfile](db)
+	ctx := context.Background()
+
+	beforeCreate := time.Now()
+	profile := createAuditedProfile(t, repo)
+
+	err := repo.UpdateInPlace(ctx, profile, func() {
+		profile.Bio = "Second bio"
+	})
+	require.NoError(t, err, "UpdateInPlace should not fail")
+	afterFirstUpdate := time.Now()
+
+	err = repo.UpdateInPlace(ctx, profile, func() {
+		profile.Bio = "Third bio"
+		profile.Website = "https://third.example.com"
+	})
+	require.NoError(t, err, "UpdateInPlace should not fail")
+
+	timeline, err := history.Timeline(ctx, profile.Id)
+	require.NoError(t, err, "Timeline should not fail")
+	require.Len(t, timeline, 3, "Expected create and two updates")
+	require.Equal(t, AuditOperationCreate, timeline[0].Operation)
+	require.Equal(t, AuditOperationUpdate, timeline[1].Operation)
+	require.Equal(t, "Third bio", timeline[2].NewValues["bio"])
+
+	state, err := history.StateAt(ctx, profile.Id, afterFirstUpdate)
+	require.NoError(t, err, "StateAt should not fail")
+	require.Equal(t, profile.Id, state.Id)
+	require.Equal(t, profile.UserId, state.UserId)
+	require.Equal(t, "Second bio", state.Bio)
+	require.Equal(t, "https://example.com", state.Website)
+
+	current, err := history.StateAt(ctx, profile.Id, time.Now())
+	require.NoError(t, err, "StateAt should not fail")
+	require.Equal(t, profile, current, "Expected replayed state to match the entity")
+
+	_, err = history.StateAt(ctx, profile.Id, beforeCreate)
+	require.True(t, errors.Is(err, gorm.ErrRecordNotFound), "Expected ErrRecordNotFound before the entity existed")
+}
+
+func TestChangeHistoryRepository_UnknownEntity(t *testing.T) {
+	db := setupTestDB(t)
+	history := NewChangeHistoryRepository[tests.TestProfile](db)
+
+	timeline, err := history.Timeline(context.Background(), uuid.New())
+	require.NoError(t, err, "Timeline should not fail")
+	require.Empty(t, timeline, "Expected empty timeline")
+}
+
+func TestChangeHistoryRepository_StateAtAfterDelete(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db, WithAuditRecorder(GormAuditRecorder{}))
+	history := NewChangeHistoryRepository[tests.TestProfile](db)
+	ctx := context.Background()
+
+	profile := createAuditedProfile(t, repo)
+	require.NoError(t, repo.DeleteById(ctx, profile.Id), "DeleteById should not fail")
+
+	_, err := history.StateAt(ctx, profile.Id, time.Now())
+	require.True(t, errors.Is(err, gorm.ErrRecordNotFound), "Expected ErrRecordNotFound after deletion")
+}
+
+// labeledEntity is an audited entity with a map field diffed key by key
+type labeledEntity struct {
+	Id     uuid.UUID         `json:"id"`
+	Labels map[string]string `gorm:"type:jsonb;serializer:json" json:"labels"`
+}
+
+func TestChangeHistoryRepository_StateAtRemovedMapKey(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[labeledEntity](db, WithAuditRecorder(GormAuditRecorder{}))
+	history := NewChangeHistoryRepository[labeledEntity](db)
+	ctx := context.Background()
+	// Only the audit trail is written: the map paths need JSONB columns
+	recordOnly := func(db *gorm.DB) error { return nil }
+
+	entity := &labeledEntity{Id: uuid.New(), Labels: map[string]string{"tier": "gold", "vip": "yes"}}
+	create, err := repo.createChange(db, entity)
+	require.NoError(t, err)
+	require.NoError(t, repo.writeTracked(ctx, db, create, recordOnly))
+
+	diff := make(map[string]interface{})
+	utils.DiffMap(diff, "labels", map[string]string{"tier": "gold"}, entity.Labels)
+	update := repo.trackChange(AuditOperationUpdate, entity.Id.String(), entity, nil, diff)
+	require.NoError(t, repo.writeTracked(ctx, db, update, recordOnly))
+
+	timeline, err := history.Timeline(ctx, entity.Id)
+	require.NoError(t, err, "Timeline should not fail")
+	require.Len(t, timeline, 2)
+	require.Equal(t, map[string]interface{}{"labels.vip": nil}, timeline[1].NewValues)
+	require.Equal(t, []string{"labels.vip"}, timeline[1].RemovedPaths)
+
+	state, err := history.StateAt(ctx, entity.Id, time.Now())
+	require.NoError(t, err, "StateAt should not fail")
+	require.Equal(t, map[string]string{"tier": "gold"}, state.Labels, "Expected the deleted key to be removed")
+}
+
+func TestChangeHistoryRepository_StateAtSameTime(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db, WithAuditRecorder(GormAuditRecorder{}))
+	history := NewChangeHistoryRepository[tests.TestProfile](db)
+	ctx := context.Background()
+	profile := createAuditedProfile(t, repo)
+
+	// Entries recorded in the same instant, stored in reverse order
+	var entries []*AuditEntry
+	for _, bio := range []string{"Second bio", "Third bio"} {
+		profile.Bio = bio
+		entry, err := repo.auditEntry(ctx, &change[tests.TestProfile]{operation: AuditOperationUpdate, entityId: profile.Id.String(), entity: profile}, map[string]interface{}{"bio": bio})
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	entries[0].CreatedAt = entries[1].CreatedAt
+	require.NoError(t, db.Create(entries[1]).Error)
+	require.NoError(t, db.Create(entries[0]).Error)
+
+	state, err := history.StateAt(ctx, profile.Id, time.Now())
+	require.NoError(t, err, "StateAt should not fail")
+	require.Equal(t, "Third bio", state.Bio, "Expected entries recorded at the same time to replay in order")
+}
@@ -0,0 +1,89 @@
+package gormrepository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// HookEvent identifies the point of a write at which a repository hook runs
+type HookEvent int
+
+const (
+	HookBeforeCreate HookEvent = iota
+	HookAfterCreate
+	HookBeforeUpdate
+	HookAfterUpdate
+	HookBeforeDelete
+	HookAfterDelete
+)
+
+// Hook is a function run by the repository around its writes. Returning an error aborts the
+// operation: before hooks prevent the write, after hooks make the method return the error
+// (the write is rolled back only when it runs within a transaction).
+type Hook[T any] func(ctx context.Context, entity *T) error
+
+// RegisterHook adds a hook run by every write of the repository at the given event,
+// in registration order. Unlike GORM model callbacks, hooks are attached to the
+// repository instance and also run for map based updates:
+//   - Create runs the create hooks
+//   - Save, UpdateById, UpdateByIdInPlace, UpdateInPlace and UpdateByIdWithMask run the
+//     update hooks with the entity; the in-place methods run before hooks after updateFunc,
+//     so changes made by hooks are part of the update
+//   - UpdateByIdWithMap runs before hooks with an entity holding only the id, and after
+//     hooks with the updated entity
+//   - DeleteById runs the delete hooks with an entity holding only the id
+//
+// Writes within a read-only transaction are rejected before any hook runs.
+// Hooks should be registered before the repository is used concurrently.
+func (r *GormRepository[T]) RegisterHook(event HookEvent, hook Hook[T]) {
+	if r.hooks == nil {
+		r.hooks = make(map[HookEvent][]Hook[T])
+	}
+	r.hooks[event] = append(r.hooks[event], hook)
+}
+
+// runHooks runs the hooks registered for event, stopping at the first error
+func (r *GormRepository[T]) runHooks(ctx context.Context, event HookEvent, entity *T) error {
+	for _, hook := range r.hooks[event] {
+		if err := hook(ctx, entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// beforeWrite prepares entity for a write: it normalizes the entity, sets its blame fields
+// (and timestamps for creations), runs the before hooks of event, then validates the entity, so values set by hooks are
+// validated too
+func (r *GormRepository[T]) beforeWrite(ctx context.Context, event HookEvent, entity *T) error {
+	r.normalize(ctx, entity)
+	r.stampActor(ctx, event, entity)
+	if event == HookBeforeCreate {
+		r.touch(entity)
+	}
+
+	if err := r.runHooks(ctx, event, entity); err != nil {
+		return err
+	}
+	return r.validate(ctx, entity)
+}
+
+// entityWithId returns a new entity with its Id field set, when it has a uuid Id field
+func entityWithId[T any](id uuid.UUID) *T {
+	entity := newEntity[T]()
+
+	value := reflect.ValueOf(&entity).Elem()
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	if value.Kind() == reflect.Struct {
+		if idField := value.FieldByName("Id"); idField.IsValid() && idField.CanSet() && idField.Type() == reflect.TypeOf(id) {
+			idField.Set(reflect.ValueOf(id))
+		}
+	}
+
+	return &entity
+}
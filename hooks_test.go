@@ -0,0 +1,90 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_Hooks_RunInOrder(t *testing.T) {
+	db := setupTestDB(t)
+	repo := &GormRepository[tests.TestProfile]{DB: db}
+	ctx := context.Background()
+
+	var calls []string
+	record := func(name string) Hook[tests.TestProfile] {
+		return func(ctx context.Context, profile *tests.TestProfile) error {
+			calls = append(calls, name+":"+profile.Id.String())
+			return nil
+		}
+	}
+	repo.RegisterHook(HookBeforeCreate, record("beforeCreate"))
+	repo.RegisterHook(HookAfterCreate, record("afterCreate"))
+	repo.RegisterHook(HookBeforeUpdate, record("beforeUpdate"))
+	repo.RegisterHook(HookAfterUpdate, record("afterUpdate"))
+	repo.RegisterHook(HookBeforeDelete, record("beforeDelete"))
+	repo.RegisterHook(HookAfterDelete, record("afterDelete"))
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Bio: "Hooked"}
+	require.NoError(t, repo.Create(ctx, profile), "Create should not fail")
+
+	_, err := repo.UpdateByIdWithMap(ctx, profile.Id, map[string]interface{}{"bio": "Map update"})
+	require.NoError(t, err, "UpdateByIdWithMap should not fail")
+
+	require.NoError(t, repo.DeleteById(ctx, profile.Id), "DeleteById should not fail")
+
+	id := profile.Id.String()
+	expected := []string{
+		"beforeCreate:" + id, "afterCreate:" + id,
+		"beforeUpdate:" + id, "afterUpdate:" + id,
+		"beforeDelete:" + id, "afterDelete:" + id,
+	}
+	require.Equal(t, expected, calls)
+}
+
+func TestGormRepository_Hooks_BeforeHookAbortsWrite(t *testing.T) {
+	db := setupTestDB(t)
+	repo := &GormRepository[tests.TestProfile]{DB: db}
+	ctx := context.Background()
+
+	errRejected := errors.New("rejected")
+	repo.RegisterHook(HookBeforeCreate, func(ctx context.Context, profile *tests.TestProfile) error {
+		return errRejected
+	})
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New()}
+	err := repo.Create(ctx, profile)
+	require.ErrorIs(t, err, errRejected)
+
+	var count int64
+	db.Model(&tests.TestProfile{}).Count(&count)
+	require.Equal(t, int64(0), count, "Expected the create to be aborted")
+}
+
+func TestGormRepository_Hooks_BeforeUpdateChangesAreWritten(t *testing.T) {
+	db := setupTestDB(t)
+	repo := &GormRepository[tests.TestProfile]{DB: db}
+	ctx := context.Background()
+
+	repo.RegisterHook(HookBeforeUpdate, func(ctx context.Context, profile *tests.TestProfile) error {
+		profile.Website = "https://hooked.example.com"
+		return nil
+	})
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Bio: "Original"}
+	require.NoError(t, repo.Create(ctx, profile), "Create should not fail")
+
+	err := repo.UpdateInPlace(ctx, profile, func() {
+		profile.Bio = "Updated"
+	})
+	require.NoError(t, err, "UpdateInPlace should not fail")
+
+	found, err := repo.FindById(ctx, profile.Id)
+	require.NoError(t, err, "FindById should not fail")
+	require.Equal(t, "Updated", found.Bio)
+	require.Equal(t, "https://hooked.example.com", found.Website)
+}
@@ -0,0 +1,78 @@
+package gormrepository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WithIdentityMap makes FindById return the same *T for an id within a transaction: the
+// entity read or created first by the transaction is returned again without querying, so two
+// copies can't diverge and overwrite each other's changes. Options other than WithTx are
+// ignored once an entity is mapped; FindOne and FindMany always query and return new copies.
+func WithIdentityMap() RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.identityMap = true
+	}
+}
+
+// identityMapTx returns the transaction the statement runs in when the identity map applies
+func (r *GormRepository[T]) identityMapTx(db *gorm.DB) (*Tx, bool) {
+	if !r.config.identityMap {
+		return nil, false
+	}
+	txInterface, inTx := db.Get(txContextKey)
+	if !inTx {
+		return nil, false
+	}
+	tx, ok := txInterface.(*Tx)
+	return tx, ok
+}
+
+// identityKey returns the identity map key of the entity of type T with the given id, in the
+// table of db
+func identityKey[T any](db *gorm.DB, id string) string {
+	return entityKeyPrefix(db, entityTypeName[T]()) + "_" + id
+}
+
+// mappedEntity returns the entity of type T with the given id mapped by the transaction
+func (r *GormRepository[T]) mappedEntity(db *gorm.DB, id uuid.UUID) (*T, bool) {
+	tx, ok := r.identityMapTx(db)
+	if !ok {
+		return nil, false
+	}
+
+	tx.mutex.RLock()
+	defer tx.mutex.RUnlock()
+	entity, found := tx.identities[identityKey[T](db, id.String())].(*T)
+	return entity, found
+}
+
+// mapEntity maps entity to its id in the transaction, unless an entity is mapped already
+func (r *GormRepository[T]) mapEntity(db *gorm.DB, entity *T) {
+	tx, ok := r.identityMapTx(db)
+	if !ok {
+		return
+	}
+
+	key := identityKey[T](db, primaryKeyString(db, entity))
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	if tx.identities == nil {
+		tx.identities = make(map[string]interface{})
+	}
+	if _, exists := tx.identities[key]; !exists {
+		tx.identities[key] = entity
+	}
+}
+
+// unmapEntity removes the entity of type T with the given id from the transaction
+func (r *GormRepository[T]) unmapEntity(db *gorm.DB, id uuid.UUID) {
+	tx, ok := r.identityMapTx(db)
+	if !ok {
+		return
+	}
+
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	delete(tx.identities, identityKey[T](db, id.String()))
+}
@@ -0,0 +1,77 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_WithIdentityMap(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithIdentityMap())
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Value: "original"}
+	require.NoError(t, repo.Create(ctx, entity))
+
+	outside, err := repo.FindById(ctx, entity.Id)
+	require.NoError(t, err)
+	again, err := repo.FindById(ctx, entity.Id)
+	require.NoError(t, err)
+	require.NotSame(t, outside, again, "Expected no identity map outside of a transaction")
+
+	tx := repo.BeginTransaction()
+	defer tx.Rollback()
+
+	first, err := repo.FindById(ctx, entity.Id, WithTx(tx))
+	require.NoError(t, err)
+	second, err := repo.FindById(ctx, entity.Id, WithTx(tx))
+	require.NoError(t, err)
+	require.Same(t, first, second, "Expected the same instance within the transaction")
+
+	created := &tests.TestSimpleEntity{Value: "created"}
+	require.NoError(t, repo.Create(ctx, created, WithTx(tx)))
+	found, err := repo.FindById(ctx, created.Id, WithTx(tx))
+	require.NoError(t, err)
+	require.Same(t, created, found, "Expected created entities to be mapped")
+
+	nested := tx.BeginTransaction()
+	require.NoError(t, repo.DeleteById(ctx, created.Id, WithTx(nested)))
+	_, err = repo.FindById(ctx, created.Id, WithTx(nested))
+	require.ErrorIs(t, err, ErrNotFound, "Expected deleted entities to be unmapped")
+	require.NoError(t, nested.Commit())
+
+	_, err = repo.FindById(ctx, created.Id, WithTx(tx))
+	require.ErrorIs(t, err, ErrNotFound, "Expected nested deletions to reach the parent")
+}
+
+func TestGormRepository_WithIdentityMap_RoutedTables(t *testing.T) {
+	db := setupTestDB(t)
+	tables := []string{"test_simple_entities_a", "test_simple_entities_b"}
+	for _, table := range tables {
+		require.NoError(t, db.Table(table).AutoMigrate(&tests.TestSimpleEntity{}))
+	}
+	t.Cleanup(func() {
+		for _, table := range tables {
+			_ = db.Migrator().DropTable(table)
+		}
+	})
+
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithIdentityMap())
+	ctx := context.Background()
+	id := uuid.New()
+	for _, table := range tables {
+		require.NoError(t, repo.Create(ctx, &tests.TestSimpleEntity{Id: id, Value: table}, WithTable(table)))
+	}
+
+	tx := repo.BeginTransaction()
+	defer tx.Rollback()
+	for _, table := range tables {
+		found, err := repo.FindById(ctx, id, WithTx(tx), WithTable(table))
+		require.NoError(t, err)
+		require.Equal(t, table, found.Value, "Expected rows of other tables sharing the id not to be mapped")
+	}
+}
@@ -0,0 +1,210 @@
+package gormrepository
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IDGenerator generates the Id of entities created without one
+type IDGenerator interface {
+	NewID() (uuid.UUID, error)
+}
+
+// IDGeneratorFunc adapts a function to the IDGenerator interface
+type IDGeneratorFunc func() (uuid.UUID, error)
+
+// NewID calls f()
+func (f IDGeneratorFunc) NewID() (uuid.UUID, error) {
+	return f()
+}
+
+var (
+	// UUIDv4Generator generates random UUIDs, the default
+	UUIDv4Generator IDGenerator = IDGeneratorFunc(uuid.NewRandom)
+	// UUIDv7Generator generates time-ordered UUIDs, which keep primary key indexes compact
+	UUIDv7Generator IDGenerator = IDGeneratorFunc(uuid.NewV7)
+	// ULIDGenerator generates ULIDs stored in the 16 bytes of a uuid.UUID: a 48 bit millisecond
+	// timestamp followed by 80 random bits, monotonic within the same millisecond.
+	// Use FormatULID for their canonical text form.
+	ULIDGenerator IDGenerator = &ulidGenerator{}
+)
+
+// UUIDVersion selects the UUID version generated for new entities
+type UUIDVersion int
+
+const (
+	// UUIDv4 generates random UUIDs
+	UUIDv4 UUIDVersion = 4
+	// UUIDv7 generates time-ordered UUIDs, which keep primary key indexes compact
+	UUIDv7 UUIDVersion = 7
+)
+
+// WithUUIDVersion selects the UUID version Create generates for entities without an Id.
+// Defaults to UUIDv4. It is a shorthand for WithIDGenerator.
+func WithUUIDVersion(version UUIDVersion) RepositoryOption {
+	if version == UUIDv7 {
+		return WithIDGenerator(UUIDv7Generator)
+	}
+	return WithIDGenerator(UUIDv4Generator)
+}
+
+// WithIDGenerator sets how Create and CreateMany generate the Id of entities without one.
+// Defaults to UUIDv4Generator.
+func WithIDGenerator(generator IDGenerator) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.idGenerator = generator
+	}
+}
+
+// assignId sets the uuid.UUID Id field of entity when it is still uuid.Nil
+func (r *GormRepository[T]) assignId(entity *T) error {
+	return r.assignIdValue(reflect.ValueOf(entity))
+}
+
+// assignIdValue is assignId for an entity of any type
+func (r *GormRepository[T]) assignIdValue(value reflect.Value) error {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	idField := value.FieldByName("Id")
+	if !idField.IsValid() || !idField.CanSet() || idField.Type() != reflect.TypeOf(uuid.Nil) || !idField.IsZero() {
+		return nil
+	}
+
+	generator := r.config.idGenerator
+	if generator == nil {
+		generator = UUIDv4Generator
+	}
+
+	id, err := generator.NewID()
+	if err != nil {
+		return err
+	}
+	idField.Set(reflect.ValueOf(id))
+	return nil
+}
+
+// assignNestedIds assigns Ids to the associated entities of value, recursively, for the
+// creations persisting associations. visited guards against cyclic references.
+func (r *GormRepository[T]) assignNestedIds(db *gorm.DB, value reflect.Value, visited map[uintptr]bool) error {
+	value = reflect.Indirect(value)
+	if value.Kind() != reflect.Struct || !value.CanAddr() || visited[value.Addr().Pointer()] {
+		return nil
+	}
+	visited[value.Addr().Pointer()] = true
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value.Addr().Interface()); err != nil {
+		return err
+	}
+
+	for _, rel := range stmt.Schema.Relationships.Relations {
+		if rel.Field.Schema != stmt.Schema {
+			// Relations declared by the related schema, for join conditions
+			continue
+		}
+		field := reflect.Indirect(rel.Field.ReflectValueOf(db.Statement.Context, value))
+		var related []reflect.Value
+		switch field.Kind() {
+		case reflect.Struct:
+			related = append(related, field)
+		case reflect.Slice:
+			for i := 0; i < field.Len(); i++ {
+				related = append(related, field.Index(i))
+			}
+		}
+
+		for _, entity := range related {
+			if entity.Kind() == reflect.Ptr && entity.IsNil() {
+				continue
+			}
+			if err := r.assignIdValue(entity); err != nil {
+				return err
+			}
+			if err := r.assignNestedIds(db, entity, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ulidGenerator generates monotonic ULIDs
+type ulidGenerator struct {
+	mutex sync.Mutex
+	last  uuid.UUID
+}
+
+func (g *ulidGenerator) NewID() (uuid.UUID, error) {
+	var id uuid.UUID
+	milliseconds := uint64(time.Now().UnixMilli())
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	// Within the same millisecond increment the previous random part, so ids keep sorting
+	// in generation order
+	if binary.BigEndian.Uint64(append([]byte{0, 0}, g.last[:6]...)) == milliseconds {
+		id = g.last
+		for i := len(id) - 1; i >= 6; i-- {
+			id[i]++
+			if id[i] != 0 {
+				break
+			}
+		}
+		g.last = id
+		return id, nil
+	}
+
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], milliseconds)
+	copy(id[:6], timestamp[2:])
+	if _, err := rand.Read(id[6:]); err != nil {
+		return uuid.Nil, err
+	}
+
+	g.last = id
+	return id, nil
+}
+
+// crockfordAlphabet is the Base32 alphabet used by ULIDs
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// FormatULID renders an id generated by ULIDGenerator in the canonical 26 character ULID form
+func FormatULID(id uuid.UUID) string {
+	var builder strings.Builder
+	builder.Grow(26)
+
+	// 128 bits are encoded as 26 characters of 5 bits, the first one holding only 3 bits
+	high := binary.BigEndian.Uint64(id[:8])
+	low := binary.BigEndian.Uint64(id[8:])
+	for i := 25; i >= 0; i-- {
+		shift := uint(i * 5)
+		var index uint64
+		switch {
+		case shift >= 64:
+			index = high >> (shift - 64)
+		case shift > 59:
+			index = low>>shift | high<<(64-shift)
+		default:
+			index = low >> shift
+		}
+		builder.WriteByte(crockfordAlphabet[index&0x1f])
+	}
+
+	return builder.String()
+}
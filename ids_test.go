@@ -0,0 +1,96 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_Create_GeneratesId(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		name    string
+		options []RepositoryOption
+		version uuid.Version
+	}{
+		{name: "default v4", version: 4},
+		{name: "v7", options: []RepositoryOption{WithUUIDVersion(UUIDv7)}, version: 7},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewGormRepository[tests.TestProfile](db, tt.options...)
+
+			profile := &tests.TestProfile{UserId: uuid.New()}
+			require.NoError(t, repo.Create(ctx, profile), "Create should not fail")
+			require.NotEqual(t, uuid.Nil, profile.Id, "Expected an Id to be generated")
+			require.Equal(t, tt.version, profile.Id.Version())
+
+			found, err := repo.FindById(ctx, profile.Id)
+			require.NoError(t, err, "FindById should not fail")
+			require.Equal(t, profile.Id, found.Id)
+		})
+	}
+}
+
+func TestGormRepository_Create_KeepsExistingId(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db)
+
+	id := uuid.New()
+	profile := &tests.TestProfile{Id: id, UserId: uuid.New()}
+	require.NoError(t, repo.Create(context.Background(), profile), "Create should not fail")
+	require.Equal(t, id, profile.Id)
+}
+
+func TestULIDGenerator_Monotonic(t *testing.T) {
+	previous, err := ULIDGenerator.NewID()
+	require.NoError(t, err, "NewID should not fail")
+
+	for i := 0; i < 1000; i++ {
+		id, err := ULIDGenerator.NewID()
+		require.NoError(t, err, "NewID should not fail")
+		require.Less(t, FormatULID(previous), FormatULID(id), "Expected ULIDs to sort in generation order")
+		previous = id
+	}
+}
+
+func TestFormatULID(t *testing.T) {
+	id := uuid.UUID{0x01, 0x56, 0x3e, 0x3a, 0xb5, 0xd3, 0xd6, 0x76, 0x4c, 0x61, 0xef, 0xb9, 0x93, 0x02, 0xbd, 0x5b}
+	require.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", FormatULID(id))
+}
+
+func TestGormRepository_CreateMany(t *testing.T) {
+	db := setupTestDB(t)
+	publisher := &recordingPublisher{}
+	repo := NewGormRepository[tests.TestProfile](db, WithIDGenerator(UUIDv7Generator), WithChangePublisher(publisher))
+	ctx := context.Background()
+
+	existingId := uuid.New()
+	profiles := []*tests.TestProfile{
+		{UserId: uuid.New(), Bio: "first"},
+		{Id: existingId, UserId: uuid.New(), Bio: "second"},
+		{UserId: uuid.New(), Bio: "third"},
+	}
+	require.NoError(t, repo.CreateMany(ctx, profiles), "CreateMany should not fail")
+
+	require.Equal(t, uuid.Version(7), profiles[0].Id.Version())
+	require.Equal(t, existingId, profiles[1].Id, "Expected existing Id to be kept")
+	require.Equal(t, uuid.Version(7), profiles[2].Id.Version())
+
+	found, err := repo.FindMany(ctx)
+	require.NoError(t, err, "FindMany should not fail")
+	require.Len(t, found, 3)
+
+	events := publisher.published()
+	require.Len(t, events, 3, "Expected one event per created entity")
+	for i, event := range events {
+		require.Equal(t, AuditOperationCreate, event.Operation)
+		require.Equal(t, profiles[i].Id.String(), event.EntityId)
+	}
+}
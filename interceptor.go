@@ -0,0 +1,84 @@
+package gormrepository
+
+import (
+	"context"
+	"time"
+)
+
+// OperationInfo describes the repository operation an interceptor wraps
+type OperationInfo struct {
+	// Method is the name of the repository method, e.g. "FindById"
+	Method string
+	// EntityType is the name of the entity type managed by the repository
+	EntityType string
+	// ReadOnly reports whether the operation only reads data
+	ReadOnly bool
+}
+
+// operationContextKey is the context key of the OperationInfo of a repository call
+type operationContextKey struct{}
+
+// Interceptor wraps every repository method call. It must call next to run the operation
+// (or skip it and return an error), and may inspect or replace the returned error.
+// This is the integration point for tracing, metrics, retries and logging.
+type Interceptor func(ctx context.Context, op OperationInfo, next func() error) error
+
+// WithInterceptors adds interceptors around the repository methods. The first interceptor
+// is the outermost one: it runs first and sees the error returned by the others.
+func WithInterceptors(interceptors ...Interceptor) RepositoryOption {
+	return func(config *repositoryConfig) {
+		for _, interceptor := range interceptors {
+			if interceptor != nil {
+				config.interceptors = append(config.interceptors, interceptor)
+			}
+		}
+	}
+}
+
+// intercept runs next through the configured interceptor chain, then records and logs the outcome.
+// next receives ctx carrying the OperationInfo of the call and doesn't run once ctx is done;
+// its errors are translated into the package errors before reaching the interceptors.
+func (r *GormRepository[T]) intercept(ctx context.Context, method string, readOnly bool, next func(ctx context.Context) error) error {
+	op := OperationInfo{
+		Method:     method,
+		EntityType: entityTypeName[T](),
+		ReadOnly:   readOnly,
+	}
+	ctx = context.WithValue(ctx, operationContextKey{}, op)
+
+	start := time.Now()
+	err := r.runInterceptors(ctx, op, func() error {
+		if err := ctx.Err(); err != nil {
+			return &DatabaseError{Kind: ErrOperationCanceled, Err: err}
+		}
+		return r.translateError(ctx, next(ctx))
+	})
+	r.stats.record(method, time.Since(start), err)
+	r.logOperation(ctx, method, start, err)
+	return err
+}
+
+// runInterceptors runs next through the configured interceptor chain
+func (r *GormRepository[T]) runInterceptors(ctx context.Context, op OperationInfo, next func() error) error {
+	if len(r.config.interceptors) == 0 {
+		return next()
+	}
+
+	// Build the chain from the innermost interceptor outwards
+	call := next
+	for i := len(r.config.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := r.config.interceptors[i], call
+		call = func() error {
+			return interceptor(ctx, op, inner)
+		}
+	}
+
+	return call()
+}
+
+// OperationFromContext returns the repository operation running with ctx. Contexts passed to
+// interceptors, hooks, validators and the other extension points of a repository call carry it.
+func OperationFromContext(ctx context.Context) (OperationInfo, bool) {
+	op, ok := ctx.Value(operationContextKey{}).(OperationInfo)
+	return op, ok
+}
@@ -0,0 +1,82 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_Interceptors_Order(t *testing.T) {
+	db := setupTestDB(t)
+
+	var calls []string
+	trace := func(name string) Interceptor {
+		return func(ctx context.Context, op OperationInfo, next func() error) error {
+			calls = append(calls, name+" before "+op.Method)
+			err := next()
+			calls = append(calls, name+" after "+op.Method)
+			return err
+		}
+	}
+
+	repo := NewGormRepository[tests.TestProfile](db, WithInterceptors(trace("outer"), trace("inner")))
+	ctx := context.Background()
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New()}
+	require.NoError(t, repo.Create(ctx, profile), "Create should not fail")
+
+	expected := []string{"outer before Create", "inner before Create", "inner after Create", "outer after Create"}
+	require.Equal(t, expected, calls)
+}
+
+func TestGormRepository_Interceptors_OperationInfo(t *testing.T) {
+	db := setupTestDB(t)
+
+	var operations []OperationInfo
+	repo := NewGormRepository[tests.TestProfile](db, WithInterceptors(
+		func(ctx context.Context, op OperationInfo, next func() error) error {
+			operations = append(operations, op)
+			return next()
+		},
+	))
+	ctx := context.Background()
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New()}
+	require.NoError(t, repo.Create(ctx, profile), "Create should not fail")
+
+	found, err := repo.FindById(ctx, profile.Id)
+	require.NoError(t, err, "FindById should not fail")
+	require.Equal(t, profile.Id, found.Id)
+
+	expected := []OperationInfo{
+		{Method: "Create", EntityType: "TestProfile", ReadOnly: false},
+		{Method: "FindById", EntityType: "TestProfile", ReadOnly: true},
+	}
+	require.Equal(t, expected, operations)
+}
+
+func TestGormRepository_Interceptors_ShortCircuit(t *testing.T) {
+	db := setupTestDB(t)
+	errBlocked := errors.New("blocked")
+
+	repo := NewGormRepository[tests.TestProfile](db, WithInterceptors(
+		func(ctx context.Context, op OperationInfo, next func() error) error {
+			if !op.ReadOnly {
+				return errBlocked
+			}
+			return next()
+		},
+	))
+	ctx := context.Background()
+
+	err := repo.Create(ctx, &tests.TestProfile{Id: uuid.New(), UserId: uuid.New()})
+	require.ErrorIs(t, err, errBlocked)
+
+	profiles, err := repo.FindMany(ctx)
+	require.NoError(t, err, "FindMany should not fail")
+	require.Empty(t, profiles, "Expected the blocked create to be skipped")
+}
@@ -0,0 +1,115 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LogLevel is the severity of a log message
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// Field is a structured attribute of a log message
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger receives the log messages of repositories and their transactions. Adapters for
+// zap, zerolog or slog (see NewSlogLogger) only have to implement Log.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, fields ...Field)
+}
+
+// WithLogger sets the logger of the repository and of the transactions it begins.
+// Repositories don't log anything without one.
+func WithLogger(logger Logger) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.logger = logger
+	}
+}
+
+// slogLogger adapts a *slog.Logger to Logger
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger writing to logger
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+// Log writes the message with the matching slog level, fields becoming attributes
+func (l *slogLogger) Log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	attrs := make([]slog.Attr, len(fields))
+	for i, field := range fields {
+		attrs[i] = slog.Any(field.Key, field.Value)
+	}
+	l.logger.LogAttrs(ctx, slogLevel(level), msg, attrs...)
+}
+
+// slogLevel maps a LogLevel to the slog level
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// log sends a message to the repository logger, if any
+func (r *GormRepository[T]) log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	if r.config.logger != nil {
+		r.config.logger.Log(ctx, level, msg, fields...)
+	}
+}
+
+// logOperation logs a completed repository operation at debug level, or at error level when
+// it failed for another reason than a missing record
+func (r *GormRepository[T]) logOperation(ctx context.Context, method string, start time.Time, err error) {
+	if r.config.logger == nil {
+		return
+	}
+
+	level := LogLevelDebug
+	fields := []Field{
+		{Key: "method", Value: method},
+		{Key: "entity", Value: entityTypeName[T]()},
+		{Key: "duration", Value: time.Since(start)},
+	}
+	if err != nil {
+		fields = append(fields, Field{Key: "error", Value: err})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			level = LogLevelError
+		}
+	}
+	r.log(ctx, level, "repository operation", fields...)
+}
+
+// log sends a message to the transaction logger, if any
+func (tx *Tx) log(level LogLevel, msg string, fields ...Field) {
+	if tx.logger == nil {
+		return
+	}
+
+	ctx := tx.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tx.logger.Log(ctx, level, msg, fields...)
+}
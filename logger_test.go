@@ -0,0 +1,34 @@
+package gormrepository
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_WithLogger(t *testing.T) {
+	db := setupTestDB(t)
+	var output bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithLogger(logger))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &tests.TestSimpleEntity{Value: "logged"}), "Create should not fail")
+	_, err := repo.FindById(ctx, uuid.New())
+	require.Error(t, err)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 2, "Expected one message per operation")
+	require.Contains(t, lines[0], "level=DEBUG")
+	require.Contains(t, lines[0], "method=Create")
+	require.Contains(t, lines[0], "entity=TestSimpleEntity")
+	require.Contains(t, lines[1], "level=DEBUG", "Expected missing records not to be logged as errors")
+	require.Contains(t, lines[1], "method=FindById")
+	require.Contains(t, lines[1], `error="record not found"`)
+}
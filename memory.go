@@ -0,0 +1,933 @@
+package gormrepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUnsupportedQuery is returned by MemoryRepository for options it can't evaluate in memory
+var ErrUnsupportedQuery = errors.New("unsupported query")
+
+// MemoryRepository is a Repository keeping entities in memory, by Id, for the unit tests of
+// services: no database, SQLite or container is needed.
+//
+//	var users gr.Repository[User] = gr.NewMemoryRepository[User]()
+//
+// Options are evaluated naively. Conditions on fields are supported: WithQueryStruct,
+// WithFilters, WithSearchLike, Column and Where("age > ?", 18) style conditions joined by AND,
+// combined with WithAnd, WithOr and Not, as well as ordering, limits and offsets. Other
+// conditions fail the call with ErrUnsupportedQuery, while joins, preloads and hints are
+// ignored. Relations are stored as set on the entities, and edited by the association methods.
+//
+// Repository options, hooks, validation, auditing and events don't apply. WithTx is
+// supported: writes are undone when the transaction rolls back. Entities are copied on the way
+// in and out, with Clone when they implement Diffable. T must have a uuid.UUID Id field.
+type MemoryRepository[T any] struct {
+	db       *gorm.DB
+	schema   *schema.Schema
+	err      error
+	mutex    sync.RWMutex
+	entities map[uuid.UUID]*T
+	// ids keeps the insertion order, the order of results without ordering
+	ids []uuid.UUID
+	// transactions holds the transactions whose rollback restores the entities
+	transactions map[*Tx]bool
+}
+
+// NewMemoryRepository returns an empty MemoryRepository
+func NewMemoryRepository[T any]() *MemoryRepository[T] {
+	repo := &MemoryRepository[T]{
+		entities:     make(map[uuid.UUID]*T),
+		transactions: make(map[*Tx]bool),
+	}
+
+	repo.db, repo.err = gorm.Open(memoryDialector{}, &gorm.Config{
+		ConnPool:             &memoryConnPool{},
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if repo.err != nil {
+		return repo
+	}
+
+	stmt := &gorm.Statement{DB: repo.db}
+	if repo.err = stmt.Parse(new(T)); repo.err != nil {
+		return repo
+	}
+	repo.schema = stmt.Schema
+	if idField, ok := stmt.Schema.ModelType.FieldByName("Id"); !ok || idField.Type != reflect.TypeOf(uuid.UUID{}) {
+		repo.err = fmt.Errorf("memory repository of %s: a uuid.UUID Id field is required", stmt.Schema.Name)
+	}
+	return repo
+}
+
+// prepare applies options to a blank query, whose clauses are evaluated by the call
+func (r *MemoryRepository[T]) prepare(ctx context.Context, options []Option) (*gorm.DB, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, &DatabaseError{Kind: ErrOperationCanceled, Err: err}
+	}
+
+	db := r.db.WithContext(ctx).Model(new(T))
+	for _, option := range options {
+		if option != nil {
+			db = option(db)
+		}
+	}
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	return db, nil
+}
+
+// write runs fn with the write lock held, saving the entities first when db is bound to a
+// transaction
+func (r *MemoryRepository[T]) write(db *gorm.DB, fn func() error) error {
+	var tx *Tx
+	if txInterface, inTx := db.Get(txContextKey); inTx {
+		tx, _ = txInterface.(*Tx)
+	}
+	if tx != nil && tx.readOnly {
+		return &DatabaseError{Kind: ErrReadOnlyTransaction, Err: ErrReadOnlyTransaction}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if tx != nil {
+		root := tx
+		for root.parent != nil {
+			root = root.parent
+		}
+		r.track(root, root)
+		r.track(tx, root)
+	}
+	return fn()
+}
+
+// track saves the entities before the first write within tx, restored when it rolls back.
+// The outermost transaction restores the entities it started with; a nested one those of its
+// savepoint, only when rolled back on its own as its parent restores older entities.
+func (r *MemoryRepository[T]) track(tx *Tx, root *Tx) {
+	if r.transactions[tx] {
+		return
+	}
+	r.transactions[tx] = true
+
+	entities := make(map[uuid.UUID]*T, len(r.entities))
+	for id, entity := range r.entities {
+		entities[id] = entity
+	}
+	ids := append([]uuid.UUID(nil), r.ids...)
+
+	tx.OnRollback(func(error) {
+		if tx != root && !tx.rolledBack {
+			return
+		}
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.entities, r.ids = entities, ids
+	})
+	if tx == root {
+		forget := func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			for tracked := range r.transactions {
+				for parent := tracked; parent != nil; parent = parent.parent {
+					if parent == root {
+						delete(r.transactions, tracked)
+						break
+					}
+				}
+			}
+		}
+		tx.OnCommit(forget)
+		tx.OnRollback(func(error) { forget() })
+	}
+}
+
+// store saves a copy of entity, the entities being replaced rather than changed in place so
+// the entities saved by track stay untouched
+func (r *MemoryRepository[T]) store(id uuid.UUID, entity *T) {
+	if _, exists := r.entities[id]; !exists {
+		r.ids = append(r.ids[:len(r.ids):len(r.ids)], id)
+	}
+	r.entities[id] = cloneEntity(entity)
+}
+
+func (r *MemoryRepository[T]) FindMany(ctx context.Context, options ...Option) ([]*T, error) {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	entities, query, err := r.find(db, r.ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*T, 0, len(entities))
+	for _, entity := range pageEntities(query, entities) {
+		entity = cloneEntity(entity)
+		storeCloneIfInTransaction(db, entity)
+		result = append(result, entity)
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository[T]) FindPaginated(ctx context.Context, page int, pageSize int, options ...Option) (*PaginationResult[*T], error) {
+	if page < 1 {
+		return nil, fmt.Errorf("%w: page %d", ErrInvalidPagination, page)
+	}
+	if pageSize < 1 {
+		return nil, fmt.Errorf("%w: page size %d", ErrInvalidPagination, pageSize)
+	}
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	entities, query, err := r.find(db, r.ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PaginationResult[*T]{Data: []*T{}, Total: int64(len(entities))}
+	offset := (page - 1) * pageSize
+	if withoutTotal, _ := db.Get(withoutTotalContextKey); withoutTotal == true {
+		result.Total = 0
+		result.TotalUnknown = true
+		result.HasNextPage = len(entities) > offset+pageSize
+	}
+	query.limit, query.offset = &pageSize, offset
+	for _, entity := range pageEntities(query, entities) {
+		entity = cloneEntity(entity)
+		storeCloneIfInTransaction(db, entity)
+		result.Data = append(result.Data, entity)
+	}
+
+	setPageMetadata(result, page, pageSize, offset)
+	return result, nil
+}
+
+func (r *MemoryRepository[T]) FindById(ctx context.Context, id uuid.UUID, options ...Option) (*T, error) {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	entities, _, err := r.find(db, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, &DatabaseError{Kind: ErrNotFound, Err: gorm.ErrRecordNotFound}
+	}
+
+	entity := cloneEntity(entities[0])
+	storeCloneIfInTransaction(db, entity)
+	return entity, nil
+}
+
+func (r *MemoryRepository[T]) FindOne(ctx context.Context, options ...Option) (*T, error) {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	entities, query, err := r.find(db, r.ids)
+	if err != nil {
+		return nil, err
+	}
+	entities = pageEntities(query, entities)
+	if len(entities) == 0 {
+		return nil, &DatabaseError{Kind: ErrNotFound, Err: gorm.ErrRecordNotFound}
+	}
+
+	entity := cloneEntity(entities[0])
+	storeCloneIfInTransaction(db, entity)
+	return entity, nil
+}
+
+// Max returns the maximum of a numeric field or column, 0 without entities
+func (r *MemoryRepository[T]) Max(ctx context.Context, column string, options ...Option) (int, error) {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return 0, err
+	}
+	field, err := r.lookUpColumn(column)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	entities, _, err := r.find(db, r.ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var max *float64
+	for _, entity := range entities {
+		value, _ := field.ValueOf(db.Statement.Context, reflect.ValueOf(entity).Elem())
+		number, ok := normalizeValue(value).(float64)
+		if !ok {
+			if normalizeValue(value) == nil {
+				continue
+			}
+			return 0, fmt.Errorf("%w: MAX of non numeric %s", ErrUnsupportedQuery, field.Name)
+		}
+		if max == nil || number > *max {
+			max = &number
+		}
+	}
+	if max == nil {
+		return 0, nil
+	}
+	return int(*max), nil
+}
+
+func (r *MemoryRepository[T]) Create(ctx context.Context, entity *T, options ...Option) error {
+	return r.CreateMany(ctx, []*T{entity}, options...)
+}
+
+func (r *MemoryRepository[T]) CreateMany(ctx context.Context, entities []*T, options ...Option) error {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	return r.write(db, func() error {
+		ids := make(map[uuid.UUID]bool, len(entities))
+		for _, entity := range entities {
+			id := r.idOf(entity)
+			if id == uuid.Nil {
+				if id, err = UUIDv4Generator.NewID(); err != nil {
+					return err
+				}
+				r.setId(entity, id)
+			}
+			if _, exists := r.entities[id]; exists || ids[id] {
+				return &DatabaseError{Kind: ErrDuplicateKey, Err: fmt.Errorf("duplicate key: %s %s already exists", r.schema.Name, id)}
+			}
+			ids[id] = true
+		}
+
+		for _, entity := range entities {
+			r.touch(db, entity, true)
+			r.store(r.idOf(entity), entity)
+			storeCloneIfInTransaction(db, entity)
+		}
+		return nil
+	})
+}
+
+// Save inserts entity, or replaces the stored entity with the same Id
+func (r *MemoryRepository[T]) Save(ctx context.Context, entity *T, options ...Option) error {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	return r.write(db, func() error {
+		id := r.idOf(entity)
+		if id == uuid.Nil {
+			if id, err = UUIDv4Generator.NewID(); err != nil {
+				return err
+			}
+			r.setId(entity, id)
+		}
+		_, exists := r.entities[id]
+		r.touch(db, entity, !exists)
+		r.store(id, entity)
+		return nil
+	})
+}
+
+// BulkUpdate sets the fields of mask, keyed by JSON name as for GormRepository, on the entities
+// matching where
+func (r *MemoryRepository[T]) BulkUpdate(ctx context.Context, where Option, mask map[string]interface{}, options ...Option) error {
+	if where == nil {
+		return fmt.Errorf("WHERE conditions are required for bulk update")
+	}
+	db, err := r.prepare(ctx, append(options[:len(options):len(options)], where))
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(mask)
+	if err != nil {
+		return err
+	}
+
+	return r.write(db, func() error {
+		entities, _, err := r.find(db, r.ids)
+		if err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			entity = cloneEntity(entity)
+			if err := json.Unmarshal(patch, entity); err != nil {
+				return err
+			}
+			r.touch(db, entity, false)
+			r.store(r.idOf(entity), entity)
+		}
+		return nil
+	})
+}
+
+// UpdateById writes the differences between entity and its snapshot, taken when it was read
+// within the transaction, or its non-zero fields otherwise, as GormRepository does
+func (r *MemoryRepository[T]) UpdateById(ctx context.Context, id uuid.UUID, entity *T, options ...Option) error {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return err
+	}
+	diffable, ok := any(entity).(Diffable[T])
+	if !ok {
+		return fmt.Errorf("entity must implement Diffable[T] interface")
+	}
+
+	clone, _ := getCloneForDiff(db, entity)
+	return r.updateFields(db, id, entity, diffable.Diff(clone))
+}
+
+// UpdateByIdWithMask writes the fields of entity listed in mask, keyed by JSON name; nested
+// maps select fields of JSON columns
+func (r *MemoryRepository[T]) UpdateByIdWithMask(ctx context.Context, id uuid.UUID, mask map[string]interface{}, entity *T, options ...Option) error {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	patch, err := json.Marshal(maskValues(values, mask))
+	if err != nil {
+		return err
+	}
+
+	return r.write(db, func() error {
+		stored, exists := r.entities[id]
+		if !exists {
+			return nil
+		}
+		updated := cloneEntity(stored)
+		if err := json.Unmarshal(patch, updated); err != nil {
+			return err
+		}
+		r.touch(db, updated, false)
+		r.store(id, updated)
+		return nil
+	})
+}
+
+// maskValues returns the values selected by mask
+func maskValues(values map[string]interface{}, mask map[string]interface{}) map[string]interface{} {
+	selected := make(map[string]interface{}, len(mask))
+	for key, nested := range mask {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		nestedMask, isMask := nested.(map[string]interface{})
+		nestedValues, isMap := value.(map[string]interface{})
+		if isMask && isMap {
+			value = maskValues(nestedValues, nestedMask)
+		}
+		selected[key] = value
+	}
+	return selected
+}
+
+// UpdateByIdWithMap sets values, keyed by field or column name, and returns the updated entity.
+// SQL expressions such as gorm.Expr aren't supported.
+func (r *MemoryRepository[T]) UpdateByIdWithMap(ctx context.Context, id uuid.UUID, values map[string]interface{}, options ...Option) (*T, error) {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newEntity[T]()
+	err = r.write(db, func() error {
+		stored, exists := r.entities[id]
+		if !exists {
+			return nil
+		}
+		updated := cloneEntity(stored)
+		target := reflect.ValueOf(updated).Elem()
+		for key, value := range values {
+			if _, ok := value.(clause.Expression); ok {
+				return fmt.Errorf("%w: expression value of %s", ErrUnsupportedQuery, key)
+			}
+			field, err := r.lookUpColumn(key)
+			if err != nil {
+				return err
+			}
+			if err := field.Set(db.Statement.Context, target, value); err != nil {
+				return err
+			}
+		}
+		r.touch(db, updated, false)
+		r.store(id, updated)
+		result = *cloneEntity(updated)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (r *MemoryRepository[T]) UpdateByIdInPlace(ctx context.Context, id uuid.UUID, entity *T, updateFunc func(), options ...Option) error {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return err
+	}
+	diffable, isDiffable := any(entity).(Diffable[T])
+	if !isDiffable {
+		return fmt.Errorf("entity does not support diffing - entity must implement Diffable[T] interface")
+	}
+
+	originalClone := diffable.Clone()
+	updateFunc()
+	return r.updateFields(db, id, entity, diffable.Diff(originalClone))
+}
+
+func (r *MemoryRepository[T]) UpdateInPlace(ctx context.Context, entity *T, updateFunc func(), options ...Option) error {
+	return r.UpdateByIdInPlace(ctx, r.idOf(entity), entity, updateFunc, options...)
+}
+
+// updateFields copies the fields of entity named by the keys of diff to the entity stored
+// with id. Dotted keys of JSON paths copy the whole JSON field.
+func (r *MemoryRepository[T]) updateFields(db *gorm.DB, id uuid.UUID, entity *T, diff map[string]interface{}) error {
+	if len(diff) == 0 {
+		return nil
+	}
+
+	err := r.write(db, func() error {
+		stored, exists := r.entities[id]
+		if !exists {
+			return nil
+		}
+		updated := cloneEntity(stored)
+		source, target := reflect.ValueOf(cloneEntity(entity)).Elem(), reflect.ValueOf(updated).Elem()
+		for key := range diff {
+			name, _, _ := strings.Cut(key, ".")
+			field, err := r.lookUpColumn(name)
+			if err != nil {
+				return err
+			}
+			field.ReflectValueOf(db.Statement.Context, target).Set(field.ReflectValueOf(db.Statement.Context, source))
+		}
+		r.touch(db, updated, false)
+		r.store(id, updated)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	refreshCloneIfInTransaction(db, entity)
+	return nil
+}
+
+func (r *MemoryRepository[T]) DeleteById(ctx context.Context, id uuid.UUID, options ...Option) error {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	return r.write(db, func() error {
+		if _, exists := r.entities[id]; !exists {
+			return nil
+		}
+		delete(r.entities, id)
+		ids := make([]uuid.UUID, 0, len(r.ids)-1)
+		for _, stored := range r.ids {
+			if stored != id {
+				ids = append(ids, stored)
+			}
+		}
+		r.ids = ids
+		return nil
+	})
+}
+
+// BeginTransaction starts a transaction whose rollback undoes the writes of the memory
+// repositories used with WithTx. Nested transactions are supported.
+func (r *MemoryRepository[T]) BeginTransaction() *Tx {
+	return &Tx{
+		gtx:            r.db.Begin(),
+		clonedEntities: newSnapshotStore(0),
+		ctx:            context.Background(),
+		startedAt:      time.Now(),
+	}
+}
+
+// GetDB returns the GORM handle the options are applied to. It has no database: statements
+// are only built, as with DryRun.
+func (r *MemoryRepository[T]) GetDB() *gorm.DB {
+	return r.db
+}
+
+// AppendAssociation adds values to the association of the stored entity, replacing the values
+// with the same primary key, and sets the association of entity to the result
+func (r *MemoryRepository[T]) AppendAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
+	return r.writeAssociation(ctx, entity, association, options, func(current []reflect.Value, keyOf func(reflect.Value) interface{}) []reflect.Value {
+		for _, value := range associationValues(values) {
+			replaced := false
+			for i := range current {
+				if keyOf(current[i]) == keyOf(value) {
+					current[i], replaced = value, true
+				}
+			}
+			if !replaced {
+				current = append(current, value)
+			}
+		}
+		return current
+	})
+}
+
+func (r *MemoryRepository[T]) RemoveAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
+	return r.writeAssociation(ctx, entity, association, options, func(current []reflect.Value, keyOf func(reflect.Value) interface{}) []reflect.Value {
+		removed := make(map[interface{}]bool)
+		for _, value := range associationValues(values) {
+			removed[keyOf(value)] = true
+		}
+		var kept []reflect.Value
+		for _, value := range current {
+			if !removed[keyOf(value)] {
+				kept = append(kept, value)
+			}
+		}
+		return kept
+	})
+}
+
+func (r *MemoryRepository[T]) ReplaceAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error {
+	return r.writeAssociation(ctx, entity, association, options, func([]reflect.Value, func(reflect.Value) interface{}) []reflect.Value {
+		return associationValues(values)
+	})
+}
+
+func (r *MemoryRepository[T]) ClearAssociation(ctx context.Context, entity *T, association string, options ...Option) error {
+	return r.writeAssociation(ctx, entity, association, options, func([]reflect.Value, func(reflect.Value) interface{}) []reflect.Value {
+		return nil
+	})
+}
+
+// writeAssociation sets the association of the entity stored with the Id of entity, and of
+// entity, to the values returned by edit for its current values
+func (r *MemoryRepository[T]) writeAssociation(ctx context.Context, entity *T, association string, options []Option, edit func(current []reflect.Value, keyOf func(reflect.Value) interface{}) []reflect.Value) error {
+	db, err := r.prepare(ctx, options)
+	if err != nil {
+		return err
+	}
+	rel, ok := r.schema.Relationships.Relations[association]
+	if !ok {
+		return fmt.Errorf("%w: %s", gorm.ErrUnsupportedRelation, association)
+	}
+	if rel.FieldSchema.PrioritizedPrimaryField == nil {
+		return fmt.Errorf("%w: %s without a single primary key", ErrUnsupportedQuery, association)
+	}
+
+	return r.write(db, func() error {
+		id := r.idOf(entity)
+		stored, exists := r.entities[id]
+		if !exists {
+			return &DatabaseError{Kind: ErrNotFound, Err: gorm.ErrRecordNotFound}
+		}
+		updated := cloneEntity(stored)
+		field := rel.Field.ReflectValueOf(ctx, reflect.ValueOf(updated).Elem())
+
+		keyOf := func(value reflect.Value) interface{} {
+			key, _ := rel.FieldSchema.PrioritizedPrimaryField.ValueOf(ctx, reflect.Indirect(value))
+			return normalizeValue(key)
+		}
+		values := edit(associationValues(field.Interface()), keyOf)
+
+		if err := setAssociation(field, values); err != nil {
+			return err
+		}
+		if err := setAssociation(rel.Field.ReflectValueOf(ctx, reflect.ValueOf(entity).Elem()), values); err != nil {
+			return err
+		}
+		r.store(id, updated)
+		return nil
+	})
+}
+
+// associationValues returns the related entities of values: an entity, a pointer to one, or
+// a slice of either
+func associationValues(values interface{}) []reflect.Value {
+	value := reflect.ValueOf(values)
+	if !value.IsValid() || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return nil
+	}
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return []reflect.Value{value}
+	}
+
+	list := make([]reflect.Value, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		if element := value.Index(i); element.Kind() != reflect.Ptr || !element.IsNil() {
+			list = append(list, element)
+		}
+	}
+	return list
+}
+
+// setAssociation sets an association field to values, the last of them for has one and
+// belongs to associations
+func setAssociation(field reflect.Value, values []reflect.Value) error {
+	convert := func(value reflect.Value, target reflect.Type) (reflect.Value, error) {
+		switch {
+		case value.Type() == target:
+			return value, nil
+		case target.Kind() == reflect.Ptr && value.Type() == target.Elem():
+			pointer := reflect.New(target.Elem())
+			pointer.Elem().Set(value)
+			return pointer, nil
+		case value.Kind() == reflect.Ptr && value.Type().Elem() == target:
+			return value.Elem(), nil
+		}
+		return reflect.Value{}, fmt.Errorf("invalid association value of type %s for %s", value.Type(), target)
+	}
+
+	if field.Kind() != reflect.Slice {
+		if len(values) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		value, err := convert(values[len(values)-1], field.Type())
+		if err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	slice := reflect.MakeSlice(field.Type(), 0, len(values))
+	for _, value := range values {
+		value, err := convert(value, field.Type().Elem())
+		if err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, value)
+	}
+	field.Set(slice)
+	return nil
+}
+
+// LoadRelation sets the relation fields of entity to those of the entity stored with its Id.
+// Nested relations such as "Posts.Tags" load the whole relation of their first part.
+func (r *MemoryRepository[T]) LoadRelation(ctx context.Context, entity *T, relations []string, options ...Option) error {
+	if _, err := r.prepare(ctx, options); err != nil || len(relations) == 0 {
+		return err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	stored, exists := r.entities[r.idOf(entity)]
+	if !exists {
+		return &DatabaseError{Kind: ErrNotFound, Err: gorm.ErrRecordNotFound}
+	}
+	source, target := reflect.ValueOf(cloneEntity(stored)).Elem(), reflect.ValueOf(entity).Elem()
+	for _, relation := range relations {
+		name, _, _ := strings.Cut(relation, ".")
+		rel, ok := r.schema.Relationships.Relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", gorm.ErrUnsupportedRelation, name)
+		}
+		rel.Field.ReflectValueOf(ctx, target).Set(rel.Field.ReflectValueOf(ctx, source))
+	}
+	return nil
+}
+
+// HasAssociation reports whether value, a related entity or its primary key, is part of the
+// association of the entity stored with the Id of entity
+func (r *MemoryRepository[T]) HasAssociation(ctx context.Context, entity *T, association string, value interface{}, options ...Option) (bool, error) {
+	if _, err := r.prepare(ctx, options); err != nil {
+		return false, err
+	}
+	rel, ok := r.schema.Relationships.Relations[association]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", gorm.ErrUnsupportedRelation, association)
+	}
+	if rel.FieldSchema.PrioritizedPrimaryField == nil {
+		return false, fmt.Errorf("%w: %s without a single primary key", ErrUnsupportedQuery, association)
+	}
+
+	key := value
+	if reflectValue := reflect.Indirect(reflect.ValueOf(value)); reflectValue.IsValid() && reflectValue.Type() == rel.FieldSchema.ModelType {
+		key, _ = rel.FieldSchema.PrioritizedPrimaryField.ValueOf(ctx, reflectValue)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	stored, exists := r.entities[r.idOf(entity)]
+	if !exists {
+		return false, nil
+	}
+	for _, related := range associationValues(rel.Field.ReflectValueOf(ctx, reflect.ValueOf(stored).Elem()).Interface()) {
+		relatedKey, _ := rel.FieldSchema.PrioritizedPrimaryField.ValueOf(ctx, reflect.Indirect(related))
+		if c, ok := compareValues(relatedKey, key); ok && c == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// idOf returns the Id of entity
+func (r *MemoryRepository[T]) idOf(entity *T) uuid.UUID {
+	id, _ := reflect.ValueOf(entity).Elem().FieldByName("Id").Interface().(uuid.UUID)
+	return id
+}
+
+// setId sets the Id of entity
+func (r *MemoryRepository[T]) setId(entity *T, id uuid.UUID) {
+	reflect.ValueOf(entity).Elem().FieldByName("Id").Set(reflect.ValueOf(id))
+}
+
+// touch sets the autoCreateTime and autoUpdateTime fields of entity, as GORM does
+func (r *MemoryRepository[T]) touch(db *gorm.DB, entity *T, create bool) {
+	ctx, value, now := db.Statement.Context, reflect.ValueOf(entity).Elem(), time.Now()
+	for _, field := range r.schema.Fields {
+		if field.AutoCreateTime == 0 && field.AutoUpdateTime == 0 {
+			continue
+		}
+		if _, isZero := field.ValueOf(ctx, value); isZero || (!create && field.AutoUpdateTime > 0) {
+			_ = field.Set(ctx, value, now)
+		}
+	}
+}
+
+// lookUpColumn resolves a field or column name, as GormRepository does, or a JSON name
+func (r *MemoryRepository[T]) lookUpColumn(name string) (*schema.Field, error) {
+	if field := r.schema.LookUpField(name); field != nil && field.DBName != "" {
+		return field, nil
+	}
+	for _, field := range r.schema.Fields {
+		if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); field.DBName != "" && jsonName == name {
+			return field, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, name)
+}
+
+// cloneEntity copies entity, with Clone when it implements Diffable
+func cloneEntity[T any](entity *T) *T {
+	if diffable, ok := any(entity).(Diffable[T]); ok {
+		return diffable.Clone()
+	}
+	clone := *entity
+	return &clone
+}
+
+// memoryDialector is the dialector of the handle of MemoryRepository, which never runs SQL
+type memoryDialector struct{}
+
+func (memoryDialector) Name() string {
+	return "memory"
+}
+
+func (memoryDialector) Initialize(*gorm.DB) error {
+	return nil
+}
+
+func (memoryDialector) Migrator(*gorm.DB) gorm.Migrator {
+	return nil
+}
+
+func (memoryDialector) DataTypeOf(*schema.Field) string {
+	return ""
+}
+
+func (memoryDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (memoryDialector) BindVarTo(writer clause.Writer, _ *gorm.Statement, _ interface{}) {
+	writer.WriteByte('?')
+}
+
+func (memoryDialector) QuoteTo(writer clause.Writer, str string) {
+	writer.WriteString(str)
+}
+
+func (memoryDialector) Explain(sql string, vars ...interface{}) string {
+	return logger.ExplainSQL(sql, nil, `'`, vars...)
+}
+
+func (memoryDialector) SavePoint(*gorm.DB, string) error {
+	return nil
+}
+
+func (memoryDialector) RollbackTo(*gorm.DB, string) error {
+	return nil
+}
+
+// memoryConnPool is the connection of the handle of MemoryRepository, whose transactions
+// don't do anything by themselves
+type memoryConnPool struct{}
+
+var errMemoryConnPool = errors.New("memory repository: no database")
+
+func (p *memoryConnPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errMemoryConnPool
+}
+
+func (p *memoryConnPool) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errMemoryConnPool
+}
+
+func (p *memoryConnPool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errMemoryConnPool
+}
+
+func (p *memoryConnPool) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func (p *memoryConnPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (p *memoryConnPool) Commit() error {
+	return nil
+}
+
+func (p *memoryConnPool) Rollback() error {
+	return nil
+}
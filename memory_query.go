@@ -0,0 +1,637 @@
+package gormrepository
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	// memoryConditionPattern matches the conditions of SQL strings a MemoryRepository evaluates:
+	// a column, an operator and a placeholder, e.g. "age >= ?" or "deleted_at IS NULL"
+	memoryConditionPattern = regexp.MustCompile(`(?i)^\s*([\w."` + "`" + `]+)\s*(=|<>|!=|>=|<=|>|<|NOT\s+IN|IN|NOT\s+I?LIKE|I?LIKE|IS\s+NOT\s+NULL|IS\s+NULL)\s*(\(\s*\?\s*\)|\?)?\s*$`)
+	memoryAndPattern       = regexp.MustCompile(`(?i)\s+AND\s+`)
+	memoryOrPattern        = regexp.MustCompile(`(?i)\s+OR\s+|\(\s*[^?\s]`)
+)
+
+// memoryQuery holds the ordering and limits of a query evaluated by a MemoryRepository
+type memoryQuery struct {
+	orders []clause.OrderByColumn
+	limit  *int
+	offset int
+}
+
+// pageEntities returns the entities within the offset and limit of query
+func pageEntities[E any](query memoryQuery, entities []E) []E {
+	if query.offset > 0 {
+		if query.offset >= len(entities) {
+			return nil
+		}
+		entities = entities[query.offset:]
+	}
+	if query.limit != nil && *query.limit >= 0 && *query.limit < len(entities) {
+		entities = entities[:*query.limit]
+	}
+	return entities
+}
+
+// find returns the entities of ids matching the WHERE clause of db, sorted by its ORDER BY
+// clause, and the ordering and limits of db
+func (r *MemoryRepository[T]) find(db *gorm.DB, ids []uuid.UUID) ([]*T, memoryQuery, error) {
+	ctx, clauses := db.Statement.Context, db.Statement.Clauses
+
+	var query memoryQuery
+	if orderBy, ok := clauses["ORDER BY"].Expression.(clause.OrderBy); ok {
+		// Expressions such as the ranking of WithFullTextSearch are ignored
+		for _, column := range orderBy.Columns {
+			orders, err := splitOrder(column)
+			if err != nil {
+				return nil, query, err
+			}
+			query.orders = append(query.orders, orders...)
+		}
+	}
+	if limit, ok := clauses["LIMIT"].Expression.(clause.Limit); ok {
+		query.limit, query.offset = limit.Limit, limit.Offset
+	}
+
+	var conditions []clause.Expression
+	if where, ok := clauses["WHERE"].Expression.(clause.Where); ok {
+		conditions = where.Exprs
+	}
+
+	var entities []*T
+	for _, id := range ids {
+		entity, ok := r.entities[id]
+		if !ok {
+			continue
+		}
+		matched, err := r.matches(ctx, reflect.ValueOf(entity).Elem(), conditions)
+		if err != nil {
+			return nil, query, err
+		}
+		if matched {
+			entities = append(entities, entity)
+		}
+	}
+
+	if len(query.orders) > 0 {
+		fields := make([]*schema.Field, len(query.orders))
+		for i, order := range query.orders {
+			field, err := r.columnField(order.Column)
+			if err != nil {
+				return nil, query, err
+			}
+			fields[i] = field
+		}
+		sort.SliceStable(entities, func(i, j int) bool {
+			a, b := reflect.ValueOf(entities[i]).Elem(), reflect.ValueOf(entities[j]).Elem()
+			for k, field := range fields {
+				x, _ := field.ValueOf(ctx, a)
+				y, _ := field.ValueOf(ctx, b)
+				// NULLs sort last in ascending order, as on Postgres
+				x, y = normalizeValue(x), normalizeValue(y)
+				if x == nil || y == nil {
+					if (x == nil) != (y == nil) {
+						return (x == nil) == query.orders[k].Desc
+					}
+					continue
+				}
+				if c, ok := compareValues(x, y); ok && c != 0 {
+					return (c < 0) != query.orders[k].Desc
+				}
+			}
+			return false
+		})
+	}
+
+	return entities, query, nil
+}
+
+// splitOrder splits the raw orderings of db.Order("name, age desc") into columns
+func splitOrder(column clause.OrderByColumn) ([]clause.OrderByColumn, error) {
+	if !column.Column.Raw {
+		return []clause.OrderByColumn{column}, nil
+	}
+
+	var columns []clause.OrderByColumn
+	for _, part := range strings.Split(column.Column.Name, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, fmt.Errorf("%w: order %q", ErrUnsupportedQuery, column.Column.Name)
+		}
+		desc := len(words) == 2 && strings.EqualFold(words[1], "desc")
+		if len(words) == 2 && !desc && !strings.EqualFold(words[1], "asc") {
+			return nil, fmt.Errorf("%w: order %q", ErrUnsupportedQuery, column.Column.Name)
+		}
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: words[0]}, Desc: desc})
+	}
+	return columns, nil
+}
+
+// matches reports whether value matches conditions, joined by AND as in a WHERE clause, but
+// for the single conditions of OrConditions, added by Or, which are joined by OR
+func (r *MemoryRepository[T]) matches(ctx context.Context, value reflect.Value, conditions []clause.Expression) (bool, error) {
+	// As clause.Where, a leading Or condition swaps places with the first other condition
+	conditions = append([]clause.Expression(nil), conditions...)
+	for i, condition := range conditions {
+		if or, ok := condition.(clause.OrConditions); !ok || len(or.Exprs) > 1 {
+			conditions[0], conditions[i] = conditions[i], conditions[0]
+			break
+		}
+	}
+
+	matched, group := false, true
+	for i, condition := range conditions {
+		if or, ok := condition.(clause.OrConditions); ok && len(or.Exprs) == 1 && i > 0 {
+			matched = matched || group
+			group = true
+			condition = or.Exprs[0]
+		}
+		ok, err := r.evaluate(ctx, value, condition)
+		if err != nil {
+			return false, err
+		}
+		group = group && ok
+	}
+	return matched || group, nil
+}
+
+// evaluate reports whether value matches condition
+func (r *MemoryRepository[T]) evaluate(ctx context.Context, value reflect.Value, condition clause.Expression) (bool, error) {
+	switch condition := condition.(type) {
+	case clause.AndConditions:
+		return r.matches(ctx, value, condition.Exprs)
+	case clause.OrConditions:
+		for _, expression := range condition.Exprs {
+			if ok, err := r.evaluate(ctx, value, expression); ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	case clause.NotConditions:
+		return r.evaluateNot(ctx, value, condition)
+	case clause.Eq:
+		return r.compare(ctx, value, condition.Column, "=", condition.Value)
+	case clause.Neq:
+		return r.compare(ctx, value, condition.Column, "<>", condition.Value)
+	case clause.Gt:
+		return r.compare(ctx, value, condition.Column, ">", condition.Value)
+	case clause.Gte:
+		return r.compare(ctx, value, condition.Column, ">=", condition.Value)
+	case clause.Lt:
+		return r.compare(ctx, value, condition.Column, "<", condition.Value)
+	case clause.Lte:
+		return r.compare(ctx, value, condition.Column, "<=", condition.Value)
+	case clause.IN:
+		return r.compare(ctx, value, condition.Column, "IN", condition.Values)
+	case clause.Like:
+		return r.compare(ctx, value, condition.Column, "LIKE", condition.Value)
+	case filterExpression:
+		return r.evaluateFilter(ctx, value, condition)
+	case likeSearch:
+		for _, field := range condition.fields {
+			if ok, err := r.compare(ctx, value, field, "ILIKE", condition.pattern); ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	case clause.Expr:
+		return r.evaluateSQL(ctx, value, condition)
+	default:
+		return false, fmt.Errorf("%w: %T condition", ErrUnsupportedQuery, condition)
+	}
+}
+
+// evaluateNot evaluates the conditions of Not as GORM writes them: each condition negated when
+// one of them can be, e.g. Eq into <>, the negation of all of them otherwise
+func (r *MemoryRepository[T]) evaluateNot(ctx context.Context, value reflect.Value, condition clause.NotConditions) (bool, error) {
+	for _, expression := range condition.Exprs {
+		if _, ok := expression.(clause.NegationExpressionBuilder); !ok {
+			continue
+		}
+		for _, expression := range condition.Exprs {
+			if ok, err := r.evaluate(ctx, value, expression); ok || err != nil {
+				return false, err
+			}
+			// As for NOT IN and NOT LIKE, negated comparisons don't match NULL either
+			if null, err := r.comparesNull(ctx, value, expression); null || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	ok, err := r.matches(ctx, value, condition.Exprs)
+	if ok || err != nil {
+		return false, err
+	}
+	return r.anyFalse(ctx, value, condition.Exprs)
+}
+
+// anyFalse reports whether one of expressions, conditions joined by AND, is false rather than
+// NULL: the negation of a comparison of a NULL column is NULL, which no row matches
+func (r *MemoryRepository[T]) anyFalse(ctx context.Context, value reflect.Value, expressions []clause.Expression) (bool, error) {
+	for _, expression := range expressions {
+		switch expression := expression.(type) {
+		case clause.OrConditions:
+			// NULL isn't told apart from false across OR: the unmatched conditions count as false
+			return true, nil
+		case clause.AndConditions:
+			if ok, err := r.anyFalse(ctx, value, expression.Exprs); ok || err != nil {
+				return ok, err
+			}
+			continue
+		}
+
+		ok, err := r.evaluate(ctx, value, expression)
+		if err != nil {
+			return false, err
+		}
+		null, err := r.comparesNull(ctx, value, expression)
+		if err != nil {
+			return false, err
+		}
+		if !ok && !null {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// comparesNull reports whether expression compares a column that is NULL in value to a value
+// other than nil, a comparison whose negation is NULL in SQL
+func (r *MemoryRepository[T]) comparesNull(ctx context.Context, value reflect.Value, expression clause.Expression) (bool, error) {
+	var column, operand interface{}
+	switch expression := expression.(type) {
+	case clause.Eq:
+		column, operand = expression.Column, expression.Value
+	case clause.Neq:
+		column, operand = expression.Column, expression.Value
+	case clause.Gt:
+		column, operand = expression.Column, expression.Value
+	case clause.Gte:
+		column, operand = expression.Column, expression.Value
+	case clause.Lt:
+		column, operand = expression.Column, expression.Value
+	case clause.Lte:
+		column, operand = expression.Column, expression.Value
+	case clause.Like:
+		column, operand = expression.Column, expression.Value
+	case clause.IN:
+		if len(expression.Values) == 0 {
+			return false, nil
+		}
+		column, operand = expression.Column, expression.Values
+	case filterExpression:
+		if expression.operator == "isnull" {
+			return false, nil
+		}
+		column, operand = expression.field, expression.value
+	default:
+		return false, nil
+	}
+	if normalizeValue(operand) == nil {
+		return false, nil
+	}
+	return r.compare(ctx, value, column, "IS NULL", nil)
+}
+
+// evaluateFilter evaluates a condition of WithFilters or Column
+func (r *MemoryRepository[T]) evaluateFilter(ctx context.Context, value reflect.Value, filter filterExpression) (bool, error) {
+	switch filter.operator {
+	case "isnull":
+		if isNull, _ := filter.value.(bool); isNull || filter.value == "true" {
+			return r.compare(ctx, value, filter.field, "IS NULL", nil)
+		}
+		return r.compare(ctx, value, filter.field, "IS NOT NULL", nil)
+	case "in":
+		return r.compare(ctx, value, filter.field, "IN", filterValues(filter.value))
+	default:
+		return r.compare(ctx, value, filter.field, filterOperators[filter.operator], filter.value)
+	}
+}
+
+// evaluateSQL evaluates SQL conditions such as db.Where("age > ? AND name = ?", 18, "x"),
+// limited to comparisons of columns joined by AND
+func (r *MemoryRepository[T]) evaluateSQL(ctx context.Context, value reflect.Value, expression clause.Expr) (bool, error) {
+	if memoryOrPattern.MatchString(expression.SQL) {
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedQuery, expression.SQL)
+	}
+
+	vars := expression.Vars
+	matched := true
+	for _, part := range memoryAndPattern.Split(expression.SQL, -1) {
+		match := memoryConditionPattern.FindStringSubmatch(part)
+		if match == nil {
+			return false, fmt.Errorf("%w: %q", ErrUnsupportedQuery, expression.SQL)
+		}
+
+		column, operator := match[1], strings.ToUpper(strings.Join(strings.Fields(match[2]), " "))
+		isNullTest := strings.HasPrefix(operator, "IS ")
+		if isNullTest == (match[3] != "") || (!isNullTest && len(vars) == 0) {
+			return false, fmt.Errorf("%w: %q", ErrUnsupportedQuery, expression.SQL)
+		}
+
+		var operand interface{}
+		if !isNullTest {
+			operand, vars = vars[0], vars[1:]
+			if _, ok := operand.(*gorm.DB); ok {
+				return false, fmt.Errorf("%w: subquery in %q", ErrUnsupportedQuery, expression.SQL)
+			}
+		}
+		negated := strings.HasPrefix(operator, "NOT ")
+		if negated {
+			operator = strings.TrimPrefix(operator, "NOT ")
+		}
+		if operator == "IN" {
+			operand = sliceValues(operand)
+		}
+		if operator == "!=" {
+			operator = "<>"
+		}
+
+		ok, err := r.compare(ctx, value, column, operator, operand)
+		if err != nil {
+			return false, err
+		}
+		// NOT IN and NOT LIKE don't match NULL either
+		if negated {
+			if null, _ := r.compare(ctx, value, column, "IS NULL", nil); !null {
+				ok = !ok
+			}
+		}
+		matched = matched && ok
+	}
+	return matched, nil
+}
+
+// compare reports whether the column of value compares to operand with operator, an SQL
+// comparison operator, IN, LIKE, ILIKE or IS [NOT] NULL. As in SQL, NULL matches no comparison.
+func (r *MemoryRepository[T]) compare(ctx context.Context, value reflect.Value, column interface{}, operator string, operand interface{}) (bool, error) {
+	field, err := r.columnField(column)
+	if err != nil {
+		return false, err
+	}
+	fieldValue, _ := field.ValueOf(ctx, value)
+	fieldValue = normalizeValue(fieldValue)
+
+	switch operator {
+	case "IS NULL":
+		return fieldValue == nil, nil
+	case "IS NOT NULL":
+		return fieldValue != nil, nil
+	case "IN":
+		for _, operand := range operand.([]interface{}) {
+			if c, ok := compareValues(fieldValue, operand); ok && c == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "LIKE", "ILIKE":
+		pattern, ok := normalizeValue(operand).(string)
+		if !ok || fieldValue == nil {
+			return false, nil
+		}
+		return likeMatches(fmt.Sprint(fieldValue), pattern, operator == "ILIKE"), nil
+	}
+
+	// GORM writes conditions on nil as IS NULL
+	if operand == nil || normalizeValue(operand) == nil {
+		switch operator {
+		case "=":
+			return fieldValue == nil, nil
+		case "<>":
+			return fieldValue != nil, nil
+		}
+	}
+
+	c, ok := compareValues(fieldValue, operand)
+	if !ok {
+		// Values without an order, such as JSON documents, still differ from each other
+		return operator == "<>" && unorderedDiffer(fieldValue, operand), nil
+	}
+	switch operator {
+	case "=":
+		return c == 0, nil
+	case "<>":
+		return c != 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	default:
+		return false, fmt.Errorf("%w: operator %s", ErrUnsupportedQuery, operator)
+	}
+}
+
+// columnField resolves the column of a condition: a clause.Column or a name, possibly quoted
+// and prefixed by its table
+func (r *MemoryRepository[T]) columnField(column interface{}) (*schema.Field, error) {
+	var name string
+	switch column := column.(type) {
+	case clause.Column:
+		if column.Name == clause.PrimaryKey && r.schema.PrioritizedPrimaryField != nil {
+			return r.schema.PrioritizedPrimaryField, nil
+		}
+		name = column.Name
+	case string:
+		name = column
+	default:
+		return nil, fmt.Errorf("%w: %T column", ErrUnsupportedQuery, column)
+	}
+
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return r.lookUpColumn(strings.Trim(name, "\"`"))
+}
+
+// sliceValues returns the values of the operand of IN
+func sliceValues(operand interface{}) []interface{} {
+	if values, ok := operand.([]interface{}); ok {
+		return values
+	}
+	value := reflect.ValueOf(operand)
+	if _, isValuer := operand.(driver.Valuer); isValuer || (value.Kind() != reflect.Slice && value.Kind() != reflect.Array) {
+		return []interface{}{operand}
+	}
+	values := make([]interface{}, value.Len())
+	for i := range values {
+		values[i] = value.Index(i).Interface()
+	}
+	return values
+}
+
+// normalizeValue converts value for comparisons: nil for NULL, float64 for numbers, string,
+// bool or time.Time for those, the value of driver.Valuer types such as uuid.UUID
+func normalizeValue(value interface{}) interface{} {
+	reflectValue := reflect.ValueOf(value)
+	for reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return nil
+		}
+		reflectValue = reflectValue.Elem()
+	}
+	if !reflectValue.IsValid() {
+		return nil
+	}
+
+	value = reflectValue.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil {
+			if v == nil {
+				return nil
+			}
+			if _, isValuer := v.(driver.Valuer); !isValuer {
+				return normalizeValue(v)
+			}
+		}
+	}
+	if t, ok := value.(time.Time); ok {
+		return t
+	}
+
+	switch reflectValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflectValue.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflectValue.Uint())
+	case reflect.Float32, reflect.Float64:
+		return reflectValue.Float()
+	case reflect.String:
+		return reflectValue.String()
+	case reflect.Bool:
+		return reflectValue.Bool()
+	}
+	if bytes, ok := value.([]byte); ok {
+		return string(bytes)
+	}
+	return value
+}
+
+// compareValues compares a and b, converting strings to the type of the other value as a
+// database would for request parameters. It reports false when they can't be compared.
+func compareValues(a, b interface{}) (int, bool) {
+	a, b = normalizeValue(a), normalizeValue(b)
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if s, ok := a.(string); ok {
+		if _, isString := b.(string); !isString {
+			c, ok := compareValues(b, s)
+			return -c, ok
+		}
+	}
+
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if s, isString := b.(string); isString {
+			parsed, err := strconv.ParseFloat(s, 64)
+			y, ok = parsed, err == nil
+		}
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		y, ok := b.(string)
+		return strings.Compare(x, y), ok
+	case bool:
+		y, ok := b.(bool)
+		if s, isString := b.(string); isString {
+			parsed, err := strconv.ParseBool(s)
+			y, ok = parsed, err == nil
+		}
+		if !ok {
+			return 0, false
+		}
+		if x == y {
+			return 0, true
+		}
+		if y {
+			return -1, true
+		}
+		return 1, true
+	case time.Time:
+		y, ok := b.(time.Time)
+		if s, isString := b.(string); isString {
+			parsed, err := time.Parse(time.RFC3339Nano, s)
+			y, ok = parsed, err == nil
+		}
+		if !ok {
+			return 0, false
+		}
+		return x.Compare(y), true
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// unorderedDiffer reports whether a and b are different values of types compareValues can't
+// order
+func unorderedDiffer(a, b interface{}) bool {
+	a, b = normalizeValue(a), normalizeValue(b)
+	for _, value := range []interface{}{a, b} {
+		switch value.(type) {
+		case nil, float64, string, bool, time.Time:
+			return false
+		}
+	}
+	return !reflect.DeepEqual(a, b)
+}
+
+// likeMatches reports whether s matches a LIKE pattern, whose wildcards are escaped with
+// backslashes
+func likeMatches(s string, pattern string, ignoreCase bool) bool {
+	var expression strings.Builder
+	expression.WriteString("^(?s)")
+	if ignoreCase {
+		expression.WriteString("(?i)")
+	}
+	escaped := false
+	for _, char := range pattern {
+		switch {
+		case escaped:
+			expression.WriteString(regexp.QuoteMeta(string(char)))
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '%':
+			expression.WriteString(".*")
+		case char == '_':
+			expression.WriteByte('.')
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	expression.WriteByte('$')
+
+	matched, _ := regexp.MatchString(expression.String(), s)
+	return matched
+}
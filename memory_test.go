@@ -0,0 +1,232 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var _ Repository[tests.TestUser] = (*MemoryRepository[tests.TestUser])(nil)
+
+// newMemoryUsers returns a memory repository holding users aged 20, 30 and 40
+func newMemoryUsers(t *testing.T) (*MemoryRepository[tests.TestUser], []*tests.TestUser) {
+	repo := NewMemoryRepository[tests.TestUser]()
+	users := []*tests.TestUser{
+		{Name: "Alice Smith", Email: "alice@example.com", Age: 30, Active: true},
+		{Name: "Bob Jones", Email: "bob@example.com", Age: 20},
+		{Name: "Carol Smith", Email: "carol@example.com", Age: 40, Active: true},
+	}
+	require.NoError(t, repo.CreateMany(context.Background(), users))
+	return repo, users
+}
+
+func names(users []*tests.TestUser) []string {
+	result := make([]string, len(users))
+	for i, user := range users {
+		result[i] = user.Name
+	}
+	return result
+}
+
+func TestMemoryRepository_FindMany(t *testing.T) {
+	repo, _ := newMemoryUsers(t)
+	ctx := context.Background()
+	ageColumn := NewColumn[int]("Age")
+
+	testCases := []struct {
+		name     string
+		options  []Option
+		expected []string
+	}{
+		{"no options", nil, []string{"Alice Smith", "Bob Jones", "Carol Smith"}},
+		{"query struct", []Option{WithQueryStruct(map[string]interface{}{"active": true})}, []string{"Alice Smith", "Carol Smith"}},
+		{"filters", []Option{WithFilters(map[string]interface{}{"age__gte": "30", "name__in": "Alice Smith,Bob Jones"}, []string{"age", "name"})}, []string{"Alice Smith"}},
+		{"column", []Option{WithSpecification(ageColumn.Lt(35))}, []string{"Alice Smith", "Bob Jones"}},
+		{"search", []Option{WithSearchLike([]string{"Name", "Email"}, "SMITH")}, []string{"Alice Smith", "Carol Smith"}},
+		{"where", []Option{WithQuery(func(db *gorm.DB) *gorm.DB { return db.Where("age > ? AND name LIKE ?", 25, "%Smith") })}, []string{"Alice Smith", "Carol Smith"}},
+		{"or", []Option{WithOr(WithSpecification(ageColumn.Eq(20)), WithSpecification(ageColumn.Eq(40)))}, []string{"Bob Jones", "Carol Smith"}},
+		{"not", []Option{WithSpecification(Not(ageColumn.Eq(30)))}, []string{"Bob Jones", "Carol Smith"}},
+		{"order and limit", []Option{WithQuery(func(db *gorm.DB) *gorm.DB { return db.Order("age desc").Limit(2) })}, []string{"Carol Smith", "Alice Smith"}},
+		{"sort from request", []Option{WithSortFromRequest("-name", map[string]string{"name": "name"}), WithQuery(func(db *gorm.DB) *gorm.DB { return db.Offset(1) })}, []string{"Bob Jones", "Alice Smith"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			users, err := repo.FindMany(ctx, tc.options...)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, names(users))
+		})
+	}
+
+	_, err := repo.FindMany(ctx, WithQuery(func(db *gorm.DB) *gorm.DB { return db.Where("age > ? OR active", 25) }))
+	require.ErrorIs(t, err, ErrUnsupportedQuery)
+	_, err = repo.FindMany(ctx, WithFilters(map[string]interface{}{"password": "x"}, []string{"name"}))
+	require.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestMemoryRepository_FindMany_Null(t *testing.T) {
+	repo, users := newMemoryUsers(t)
+	ctx := context.Background()
+	archivedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := repo.UpdateByIdWithMap(ctx, users[0].Id, map[string]interface{}{"archivedAt": archivedAt})
+	require.NoError(t, err)
+	archivedColumn := NewColumn[time.Time]("ArchivedAt")
+
+	// As in SQL, negated comparisons don't match NULL
+	found, err := repo.FindMany(ctx, WithSpecification(Not(archivedColumn.Eq(archivedAt.Add(time.Hour)))))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice Smith"}, names(found))
+	found, err = repo.FindMany(ctx, WithSpecification(Not(archivedColumn.Gt(archivedAt))))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice Smith"}, names(found))
+	found, err = repo.FindMany(ctx, WithQuery(func(db *gorm.DB) *gorm.DB {
+		return db.Not(map[string]interface{}{"archivedAt": archivedAt.Add(time.Hour)})
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice Smith"}, names(found))
+	found, err = repo.FindMany(ctx, WithSpecification(Not(archivedColumn.IsNull())))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice Smith"}, names(found))
+}
+
+func TestCompareValues_Unordered(t *testing.T) {
+	type point struct{ X, Y int }
+
+	c, ok := compareValues(point{1, 2}, point{1, 2})
+	require.True(t, ok)
+	require.Zero(t, c)
+	_, ok = compareValues(point{1, 2}, point{2, 1})
+	require.False(t, ok, "Expected values without an order not to compare")
+	require.True(t, unorderedDiffer(point{1, 2}, point{2, 1}))
+}
+
+func TestMemoryRepository_Reads(t *testing.T) {
+	repo, users := newMemoryUsers(t)
+	ctx := context.Background()
+
+	found, err := repo.FindById(ctx, users[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, "Alice Smith", found.Name)
+
+	// Entities are copies: changing them doesn't change the repository
+	found.Name = "Changed"
+	found, err = repo.FindById(ctx, users[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, "Alice Smith", found.Name)
+
+	_, err = repo.FindById(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = repo.FindById(ctx, users[0].Id, WithQueryStruct(map[string]interface{}{"active": false}))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	one, err := repo.FindOne(ctx, WithQueryStruct(map[string]interface{}{"email": "bob@example.com"}))
+	require.NoError(t, err)
+	require.Equal(t, users[1].Id, one.Id)
+
+	page, err := repo.FindPaginated(ctx, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), page.Total)
+	require.Equal(t, 2, page.LastPage)
+	require.Equal(t, []string{"Carol Smith"}, names(page.Data))
+	require.True(t, page.HasPreviousPage)
+
+	max, err := repo.Max(ctx, "age", WithQueryStruct(map[string]interface{}{"active": true}))
+	require.NoError(t, err)
+	require.Equal(t, 40, max)
+}
+
+func TestMemoryRepository_Writes(t *testing.T) {
+	repo, users := newMemoryUsers(t)
+	ctx := context.Background()
+
+	require.NotEqual(t, uuid.Nil, users[0].Id, "Expected Create to assign an Id")
+	err := repo.Create(ctx, &tests.TestUser{Id: users[0].Id, Name: "Duplicate"})
+	require.ErrorIs(t, err, ErrDuplicateKey)
+
+	require.NoError(t, repo.UpdateById(ctx, users[0].Id, &tests.TestUser{Name: "Alice Brown"}))
+	found, err := repo.FindById(ctx, users[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, "Alice Brown", found.Name)
+	require.Equal(t, 30, found.Age, "Expected the zero fields not to be written")
+
+	require.NoError(t, repo.UpdateInPlace(ctx, found, func() { found.Age = 31 }))
+	updated, err := repo.UpdateByIdWithMap(ctx, users[0].Id, map[string]interface{}{"active": false})
+	require.NoError(t, err)
+	require.Equal(t, 31, updated.Age)
+	require.False(t, updated.Active)
+
+	require.NoError(t, repo.BulkUpdate(ctx, WithQueryStruct(map[string]interface{}{"active": true}), map[string]interface{}{"age": 50}))
+	old, err := repo.FindMany(ctx, WithSpecification(NewColumn[int]("Age").Eq(50)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Carol Smith"}, names(old))
+
+	require.NoError(t, repo.DeleteById(ctx, users[1].Id))
+	_, err = repo.FindById(ctx, users[1].Id)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepository_Transaction(t *testing.T) {
+	repo, users := newMemoryUsers(t)
+	ctx := context.Background()
+
+	tx := repo.BeginTransaction()
+	require.NoError(t, repo.DeleteById(ctx, users[0].Id, WithTx(tx)))
+
+	nested := tx.BeginTransaction()
+	require.NoError(t, repo.Create(ctx, &tests.TestUser{Name: "Dave"}, WithTx(nested)))
+	require.NoError(t, repo.DeleteById(ctx, users[1].Id, WithTx(nested)))
+	require.NoError(t, nested.Rollback())
+
+	all, err := repo.FindMany(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Bob Jones", "Carol Smith"}, names(all), "Expected the nested rollback to undo its writes only")
+
+	require.NoError(t, tx.Rollback())
+	all, err = repo.FindMany(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice Smith", "Bob Jones", "Carol Smith"}, names(all))
+
+	tx = repo.BeginTransaction()
+	require.NoError(t, repo.DeleteById(ctx, users[2].Id, WithTx(tx)))
+	require.NoError(t, tx.Commit())
+	_, err = repo.FindById(ctx, users[2].Id)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepository_Associations(t *testing.T) {
+	repo, users := newMemoryUsers(t)
+	ctx := context.Background()
+	user := users[0]
+	first, second := &tests.TestPost{Id: uuid.New(), Title: "First"}, &tests.TestPost{Id: uuid.New(), Title: "Second"}
+
+	require.NoError(t, repo.AppendAssociation(ctx, user, "Posts", []*tests.TestPost{first, second}))
+	require.Len(t, user.Posts, 2)
+
+	has, err := repo.HasAssociation(ctx, user, "Posts", second.Id)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	require.NoError(t, repo.RemoveAssociation(ctx, user, "Posts", second))
+	has, err = repo.HasAssociation(ctx, user, "Posts", second)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	loaded := &tests.TestUser{Id: user.Id}
+	require.NoError(t, repo.LoadRelation(ctx, loaded, []string{"Posts"}))
+	require.Len(t, loaded.Posts, 1)
+	require.Equal(t, "First", loaded.Posts[0].Title)
+	require.Empty(t, loaded.Name, "Expected only the relations to be loaded")
+
+	require.NoError(t, repo.ClearAssociation(ctx, user, "Posts"))
+	require.Empty(t, user.Posts)
+
+	err = repo.AppendAssociation(ctx, user, "Unknown", first)
+	require.True(t, errors.Is(err, gorm.ErrUnsupportedRelation))
+}
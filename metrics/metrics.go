@@ -0,0 +1,86 @@
+// Package metrics exposes Prometheus metrics for gorm-repository repositories.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	gormrepository "github.com/ikateclab/gorm-repository"
+)
+
+// Collector records the latency and errors of repository operations and the duration of
+// repository transactions. A single collector is shared by every repository it instruments.
+type Collector struct {
+	operationDuration   *prometheus.HistogramVec
+	operationErrors     *prometheus.CounterVec
+	transactionDuration *prometheus.HistogramVec
+}
+
+// NewCollector creates a Collector and registers its metrics on registerer:
+//   - gorm_repository_operation_duration_seconds, by method and entity
+//   - gorm_repository_operation_errors_total, by method and entity
+//   - gorm_repository_transaction_duration_seconds, by outcome ("commit" or "rollback")
+func NewCollector(registerer prometheus.Registerer) (*Collector, error) {
+	c := &Collector{
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "gorm_repository",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of repository operations.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "entity"}),
+		operationErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "gorm_repository",
+			Name:      "operation_errors_total",
+			Help:      "Repository operations that returned an error.",
+		}, []string{"method", "entity"}),
+		transactionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "gorm_repository",
+			Name:      "transaction_duration_seconds",
+			Help:      "Duration of repository transactions, from begin to commit or rollback.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+	}
+
+	for _, collector := range []prometheus.Collector{c.operationDuration, c.operationErrors, c.transactionDuration} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Options returns the repository options instrumenting a repository with the collector
+func (c *Collector) Options() []gormrepository.RepositoryOption {
+	return []gormrepository.RepositoryOption{
+		gormrepository.WithInterceptors(c.Interceptor()),
+		gormrepository.WithTxEndHooks(c.TxEndHook()),
+	}
+}
+
+// Interceptor returns the interceptor recording operation latency and errors
+func (c *Collector) Interceptor() gormrepository.Interceptor {
+	return func(ctx context.Context, op gormrepository.OperationInfo, next func() error) error {
+		start := time.Now()
+		err := next()
+
+		c.operationDuration.WithLabelValues(op.Method, op.EntityType).Observe(time.Since(start).Seconds())
+		if err != nil {
+			c.operationErrors.WithLabelValues(op.Method, op.EntityType).Inc()
+		}
+		return err
+	}
+}
+
+// TxEndHook returns the hook recording transaction durations
+func (c *Collector) TxEndHook() gormrepository.TxEndHook {
+	return func(ctx context.Context, committed bool, duration time.Duration) {
+		outcome := "rollback"
+		if committed {
+			outcome = "commit"
+		}
+		c.transactionDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+	}
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	gormrepository "github.com/ikateclab/gorm-repository"
+)
+
+func TestCollector(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector, err := NewCollector(registry)
+	require.NoError(t, err, "NewCollector should not fail")
+
+	ctx := context.Background()
+	intercept := collector.Interceptor()
+	op := gormrepository.OperationInfo{Method: "FindById", EntityType: "User", ReadOnly: true}
+
+	require.NoError(t, intercept(ctx, op, func() error { return nil }))
+	failure := errors.New("failure")
+	require.ErrorIs(t, intercept(ctx, op, func() error { return failure }), failure)
+
+	require.Equal(t, 1, testutil.CollectAndCount(collector.operationDuration))
+	require.Equal(t, float64(1), testutil.ToFloat64(collector.operationErrors.WithLabelValues("FindById", "User")))
+
+	end := collector.TxEndHook()
+	end(ctx, true, time.Millisecond)
+	end(ctx, false, time.Millisecond)
+	require.Equal(t, 2, testutil.CollectAndCount(collector.transactionDuration))
+
+	_, err = NewCollector(registry)
+	require.Error(t, err, "Expected registering twice on the same registry to fail")
+}
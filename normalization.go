@@ -0,0 +1,85 @@
+package gormrepository
+
+import (
+	"context"
+	"reflect"
+	"strings"
+)
+
+// Normalizable is implemented by entities that normalize their own values before being
+// written (trimming strings, lowercasing emails, ...)
+type Normalizable interface {
+	Normalize(ctx context.Context)
+}
+
+// Normalizer normalizes entities before they are written
+type Normalizer interface {
+	Normalize(ctx context.Context, entity interface{})
+}
+
+// NormalizerFunc adapts a function to the Normalizer interface
+type NormalizerFunc func(ctx context.Context, entity interface{})
+
+// Normalize calls f(ctx, entity)
+func (f NormalizerFunc) Normalize(ctx context.Context, entity interface{}) {
+	f(ctx, entity)
+}
+
+// TrimSpaceNormalizer trims leading and trailing white space of the exported string
+// and *string fields of entities. Nested structs and JSONB fields are left untouched.
+var TrimSpaceNormalizer = NormalizerFunc(func(ctx context.Context, entity interface{}) {
+	value := reflect.ValueOf(entity)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, field := range reflect.VisibleFields(value.Type()) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		// Fields promoted from nil embedded pointers can't be reached
+		fieldValue, err := value.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case fieldValue.Kind() == reflect.String:
+			fieldValue.SetString(strings.TrimSpace(fieldValue.String()))
+		case fieldValue.Kind() == reflect.Ptr && fieldValue.Type().Elem().Kind() == reflect.String && !fieldValue.IsNil():
+			fieldValue.Elem().SetString(strings.TrimSpace(fieldValue.Elem().String()))
+		}
+	}
+})
+
+// WithNormalizers normalizes entities with the normalizers, in order, before Create, Save and
+// the entity based updates. Normalization runs before the diff is computed, so normalized
+// values are written once and don't show up as changes on the next update. Entities
+// implementing Normalizable are normalized first, with or without normalizers.
+func WithNormalizers(normalizers ...Normalizer) RepositoryOption {
+	return func(config *repositoryConfig) {
+		for _, normalizer := range normalizers {
+			if normalizer != nil {
+				config.normalizers = append(config.normalizers, normalizer)
+			}
+		}
+	}
+}
+
+// normalize runs the entity's own normalization and then the configured normalizers
+func (r *GormRepository[T]) normalize(ctx context.Context, entity *T) {
+	if normalizable, ok := any(entity).(Normalizable); ok {
+		normalizable.Normalize(ctx)
+	}
+
+	for _, normalizer := range r.config.normalizers {
+		normalizer.Normalize(ctx, entity)
+	}
+}
@@ -0,0 +1,57 @@
+package gormrepository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimSpaceNormalizer(t *testing.T) {
+	nickname := "  Johnny  "
+	entity := &struct {
+		Name     string
+		Nickname *string
+		Missing  *string
+		Age      int
+	}{Name: "  John ", Nickname: &nickname, Age: 30}
+
+	TrimSpaceNormalizer.Normalize(context.Background(), entity)
+
+	require.Equal(t, "John", entity.Name)
+	require.Equal(t, "Johnny", *entity.Nickname)
+	require.Nil(t, entity.Missing)
+	require.Equal(t, 30, entity.Age)
+}
+
+func TestGormRepository_Normalizers_NoSpuriousDiff(t *testing.T) {
+	db := setupTestDB(t)
+	lowercaseWebsite := NormalizerFunc(func(ctx context.Context, entity interface{}) {
+		profile := entity.(*tests.TestProfile)
+		profile.Website = strings.ToLower(profile.Website)
+	})
+	publisher := &recordingPublisher{}
+	repo := NewGormRepository[tests.TestProfile](db,
+		WithNormalizers(TrimSpaceNormalizer, lowercaseWebsite),
+		WithChangePublisher(publisher),
+	)
+	ctx := context.Background()
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Bio: "  Bio  ", Website: " HTTPS://Example.com "}
+	require.NoError(t, repo.Create(ctx, profile), "Create should not fail")
+
+	found, err := repo.FindById(ctx, profile.Id)
+	require.NoError(t, err, "FindById should not fail")
+	require.Equal(t, "Bio", found.Bio)
+	require.Equal(t, "https://example.com", found.Website)
+
+	// Setting the same value again in a non-normalized form is not a change
+	err = repo.UpdateInPlace(ctx, found, func() {
+		found.Website = "https://EXAMPLE.com  "
+	})
+	require.NoError(t, err, "UpdateInPlace should not fail")
+	require.Len(t, publisher.published(), 1, "Expected only the create to be published")
+}
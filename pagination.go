@@ -0,0 +1,198 @@
+package gormrepository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+const (
+	estimatedCountContextKey = "__estimated_count"
+	withoutTotalContextKey   = "__without_total"
+)
+
+// ErrInvalidPagination is returned by FindPaginated for a page below 1, or a page size below 1
+// without a default page size
+var ErrInvalidPagination = errors.New("invalid pagination")
+
+// WithPageSize bounds the page sizes of FindPaginated: sizes below 1 use defaultSize and sizes
+// above maxSize are clamped to it, so a request can't read a whole table at once. 0 leaves
+// either bound unset.
+func WithPageSize(defaultSize int, maxSize int) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.defaultPageSize = defaultSize
+		config.maxPageSize = maxSize
+	}
+}
+
+// pageSize validates page and returns the page size to use for pageSize
+func (r *GormRepository[T]) pageSize(page int, pageSize int) (int, error) {
+	if page < 1 {
+		return 0, fmt.Errorf("%w: page %d", ErrInvalidPagination, page)
+	}
+	if pageSize < 1 {
+		if r.config.defaultPageSize < 1 {
+			return 0, fmt.Errorf("%w: page size %d", ErrInvalidPagination, pageSize)
+		}
+		pageSize = r.config.defaultPageSize
+	}
+	if r.config.maxPageSize > 0 && pageSize > r.config.maxPageSize {
+		pageSize = r.config.maxPageSize
+	}
+	return pageSize, nil
+}
+
+// WithEstimatedCount returns an option making FindPaginated use the row estimate of the Postgres
+// planner instead of COUNT(*) when it reaches threshold rows, setting Approximate on the result.
+// Smaller results, and other dialects, are counted exactly.
+func WithEstimatedCount(threshold int64) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(estimatedCountContextKey, threshold)
+	}
+}
+
+// WithParallelPagination makes FindPaginated run its count and page queries concurrently on
+// separate connections, outside of transactions. It needs a connection pool whose connections
+// all see the same data, which excludes SQLite :memory: databases.
+func WithParallelPagination() RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.parallelPaging = true
+	}
+}
+
+// WithoutTotal returns an option making FindPaginated skip the count query: it reads one row
+// more than the page size to set HasNextPage, and leaves Total and LastPage unset, flagged by
+// TotalUnknown. Infinite scrolling rarely needs the total, the most expensive part of a page.
+func WithoutTotal() Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(withoutTotalContextKey, true)
+	}
+}
+
+// setPageMetadata fills the page fields of result from its Data and Total
+func setPageMetadata[E any](result *PaginationResult[E], page int, pageSize int, offset int) {
+	result.Limit = pageSize
+	result.Offset = offset
+	result.CurrentPage = page
+	if len(result.Data) > 0 {
+		result.From = offset + 1
+		result.To = offset + len(result.Data)
+	}
+	if !result.TotalUnknown {
+		result.LastPage = int((result.Total + int64(pageSize) - 1) / int64(pageSize))
+		result.HasNextPage = page < result.LastPage
+	}
+	result.HasPreviousPage = page > 1
+	if result.HasNextPage {
+		next := page + 1
+		result.NextPage = &next
+	}
+	if result.HasPreviousPage {
+		previous := page - 1
+		result.PreviousPage = &previous
+	}
+}
+
+// findPage runs the count and page queries of FindPaginated, concurrently when the repository
+// uses WithParallelPagination and db isn't bound to a transaction. It fills the Data, Total,
+// Approximate, TotalUnknown and, without total, HasNextPage fields of the result.
+func (r *GormRepository[T]) findPage(db *gorm.DB, offset int, limit int) (*PaginationResult[*T], error) {
+	result := &PaginationResult[*T]{}
+
+	if withoutTotal, _ := db.Get(withoutTotalContextKey); withoutTotal == true {
+		if err := db.Offset(offset).Limit(limit + 1).Find(&result.Data).Error; err != nil {
+			return nil, err
+		}
+		if len(result.Data) > limit {
+			result.Data = result.Data[:limit]
+			result.HasNextPage = true
+		}
+		result.TotalUnknown = true
+		return result, nil
+	}
+
+	if !r.config.parallelPaging || inTransaction(db) {
+		var err error
+		if result.Total, result.Approximate, err = countRows[T](db); err != nil {
+			return nil, err
+		}
+		if err = db.Offset(offset).Limit(limit).Find(&result.Data).Error; err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	// Each query gets its own statement, as chained calls on db may change the one it holds
+	var (
+		wg       sync.WaitGroup
+		countErr error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		result.Total, result.Approximate, countErr = countRows[T](db.Session(&gorm.Session{}))
+	}()
+	err := db.Session(&gorm.Session{}).Offset(offset).Limit(limit).Find(&result.Data).Error
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	if countErr != nil {
+		return nil, countErr
+	}
+	return result, nil
+}
+
+// countRows counts the rows matched by db for FindPaginated, reporting whether the count is
+// an estimate
+func countRows[T any](db *gorm.DB) (int64, bool, error) {
+	if value, ok := db.Get(estimatedCountContextKey); ok && db.Dialector.Name() == "postgres" {
+		threshold, _ := value.(int64)
+		estimate, err := estimateRows[T](db)
+		if err == nil && estimate >= threshold {
+			return estimate, true, nil
+		}
+	}
+
+	var total int64
+	err := db.Model(new(T)).Count(&total).Error
+	return total, false, err
+}
+
+// estimateRows returns the number of rows the planner expects the query of db to return
+func estimateRows[T any](db *gorm.DB) (int64, error) {
+	var entities []*T
+	stmt := db.Session(&gorm.Session{DryRun: true}).Find(&entities).Statement
+	if stmt.Error != nil {
+		return 0, stmt.Error
+	}
+
+	var plan string
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Raw("EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).
+		Row().Scan(&plan)
+	if err != nil {
+		return 0, err
+	}
+	return planRows(plan)
+}
+
+// planRows reads the estimated row count of the top node of a JSON query plan
+func planRows(plan string) (int64, error) {
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &plans); err != nil {
+		return 0, err
+	}
+	if len(plans) == 0 {
+		return 0, fmt.Errorf("empty query plan")
+	}
+	return int64(plans[0].Plan.Rows), nil
+}
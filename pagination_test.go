@@ -0,0 +1,166 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func createSimpleEntities(t *testing.T, repo *GormRepository[tests.TestSimpleEntity], count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		require.NoError(t, repo.Create(context.Background(), &tests.TestSimpleEntity{Value: string(rune('a' + i))}))
+	}
+}
+
+func TestGormRepository_FindPaginated_WithEstimatedCount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+	createSimpleEntities(t, repo, 3)
+
+	result, err := repo.FindPaginated(ctx, 1, 2, WithEstimatedCount(1))
+	require.NoError(t, err)
+	require.Len(t, result.Data, 2)
+	if db.Dialector.Name() == "postgres" {
+		require.True(t, result.Approximate, "Expected the planner estimate")
+	} else {
+		require.False(t, result.Approximate, "Expected an exact count without Postgres")
+		require.Equal(t, int64(3), result.Total)
+	}
+}
+
+func TestPlanRows(t *testing.T) {
+	rows, err := planRows(`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 52400, "Plan Width": 36}}]`)
+	require.NoError(t, err)
+	require.Equal(t, int64(52400), rows)
+
+	_, err = planRows(`[]`)
+	require.Error(t, err)
+}
+
+func TestGormRepository_FindPaginated_WithParallelPagination(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithParallelPagination())
+	ctx := context.Background()
+	createSimpleEntities(t, repo, 5)
+
+	result, err := repo.FindPaginated(ctx, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), result.Total)
+	require.Len(t, result.Data, 2)
+	require.Equal(t, 3, result.LastPage)
+
+	err = repo.RunInTransaction(ctx, func(tx *Tx) error {
+		result, err := repo.FindPaginated(ctx, 3, 2, WithTx(tx))
+		require.NoError(t, err, "Expected transactions to paginate sequentially")
+		require.Equal(t, int64(5), result.Total)
+		require.Len(t, result.Data, 1)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// Run with -race: the count and page queries must not share the statement of the routed table
+func TestGormRepository_FindPaginated_WithParallelPaginationAndTableRouter(t *testing.T) {
+	db := setupTestDB(t)
+	table := "test_simple_entities_parallel"
+	require.NoError(t, db.Table(table).AutoMigrate(&tests.TestSimpleEntity{}))
+	t.Cleanup(func() { _ = db.Migrator().DropTable(table) })
+
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithParallelPagination(), WithTableRouter(func(ctx context.Context, entity interface{}) string {
+		return table
+	}))
+	ctx := context.Background()
+	createSimpleEntities(t, repo, 5)
+
+	for page := 1; page <= 3; page++ {
+		result, err := repo.FindPaginated(ctx, page, 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), result.Total)
+		require.Len(t, result.Data, min(2, 5-(page-1)*2))
+	}
+}
+
+func TestGormRepository_FindPaginated_WithoutTotal(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+	createSimpleEntities(t, repo, 5)
+
+	result, err := repo.FindPaginated(ctx, 2, 2, WithoutTotal())
+	require.NoError(t, err)
+	require.Len(t, result.Data, 2)
+	require.True(t, result.HasNextPage)
+	require.True(t, result.TotalUnknown)
+	require.Zero(t, result.Total)
+	require.Zero(t, result.LastPage)
+
+	result, err = repo.FindPaginated(ctx, 3, 2, WithoutTotal())
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	require.False(t, result.HasNextPage)
+
+	result, err = repo.FindPaginated(ctx, 2, 2)
+	require.NoError(t, err)
+	require.False(t, result.TotalUnknown)
+	require.True(t, result.HasNextPage, "Expected HasNextPage to be set from the total")
+}
+
+func TestGormRepository_FindPaginated_PageMetadata(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+	createSimpleEntities(t, repo, 5)
+
+	first, err := repo.FindPaginated(ctx, 1, 2)
+	require.NoError(t, err)
+	require.False(t, first.HasPreviousPage)
+	require.Nil(t, first.PreviousPage)
+	require.True(t, first.HasNextPage)
+	require.Equal(t, 2, *first.NextPage)
+	require.Equal(t, 1, first.From)
+	require.Equal(t, 2, first.To)
+
+	last, err := repo.FindPaginated(ctx, 3, 2)
+	require.NoError(t, err)
+	require.True(t, last.HasPreviousPage)
+	require.Equal(t, 2, *last.PreviousPage)
+	require.False(t, last.HasNextPage)
+	require.Nil(t, last.NextPage)
+	require.Equal(t, 5, last.From)
+	require.Equal(t, 5, last.To)
+
+	empty, err := repo.FindPaginated(ctx, 4, 2)
+	require.NoError(t, err)
+	require.Empty(t, empty.Data)
+	require.Zero(t, empty.From, "Expected no From on an empty page")
+	require.Zero(t, empty.To, "Expected no To on an empty page")
+}
+
+func TestGormRepository_FindPaginated_WithPageSize(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithPageSize(2, 3))
+	ctx := context.Background()
+	createSimpleEntities(t, repo, 5)
+
+	result, err := repo.FindPaginated(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Limit, "Expected the default page size")
+	require.Len(t, result.Data, 2)
+
+	result, err = repo.FindPaginated(ctx, 1, 1000000)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Limit, "Expected the page size to be clamped")
+	require.Len(t, result.Data, 3)
+	require.Equal(t, 2, result.LastPage)
+
+	_, err = repo.FindPaginated(ctx, 0, 2)
+	require.ErrorIs(t, err, ErrInvalidPagination)
+
+	unbounded := NewGormRepository[tests.TestSimpleEntity](db)
+	_, err = unbounded.FindPaginated(ctx, 1, 0)
+	require.ErrorIs(t, err, ErrInvalidPagination, "Expected an error without default page size")
+}
@@ -0,0 +1,29 @@
+package gormrepository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurePool(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+
+	stats, err := repo.DBStats()
+	require.NoError(t, err, "DBStats should not fail")
+	previous := stats.MaxOpenConnections
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		sqlDB.SetMaxOpenConns(previous)
+	})
+
+	require.NoError(t, ConfigurePool(db, PoolConfig{MaxOpenConns: 7, ConnMaxIdleTime: time.Minute}), "ConfigurePool should not fail")
+
+	stats, err = repo.DBStats()
+	require.NoError(t, err, "DBStats should not fail")
+	require.Equal(t, 7, stats.MaxOpenConnections)
+}
@@ -0,0 +1,21 @@
+package gormrepository
+
+import (
+	"gorm.io/gorm"
+)
+
+// WithPreparedStatements makes every method of the repository run its queries as cached
+// prepared statements, so hot paths such as FindById in loops reuse their statement.
+// The cache is shared with other sessions of the same GORM connection.
+func WithPreparedStatements() RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.prepareStmt = true
+	}
+}
+
+// WithPrepareStmt returns an option to run a single call as a cached prepared statement
+func WithPrepareStmt() Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Session(&gorm.Session{PrepareStmt: true})
+	}
+}
@@ -0,0 +1,39 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormRepository_WithPreparedStatements(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithPreparedStatements())
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "prepared"}
+	require.NoError(t, repo.Create(ctx, entity), "Create should not fail")
+
+	prepared, ok := repo.GetDB().Statement.ConnPool.(*gorm.PreparedStmtDB)
+	require.True(t, ok, "Expected the repository to use prepared statements")
+	statements := len(prepared.Stmts.Keys())
+
+	for i := 0; i < 3; i++ {
+		found, err := repo.FindById(ctx, entity.Id)
+		require.NoError(t, err, "FindById should not fail")
+		require.Equal(t, entity.Value, found.Value)
+	}
+	require.Equal(t, statements+1, len(prepared.Stmts.Keys()), "Expected FindById to reuse a single statement")
+
+	tx := repo.BeginTransaction()
+	require.NoError(t, repo.UpdateById(ctx, entity.Id, &tests.TestSimpleEntity{Value: "updated"}, WithTx(tx)))
+	require.NoError(t, tx.Commit(), "Commit should not fail")
+
+	found, err := NewGormRepository[tests.TestSimpleEntity](db).FindById(ctx, entity.Id, WithPrepareStmt())
+	require.NoError(t, err, "FindById with WithPrepareStmt should not fail")
+	require.Equal(t, "updated", found.Value)
+}
@@ -0,0 +1,36 @@
+package gormrepository
+
+import (
+	"context"
+	"time"
+)
+
+// ChangeEvent describes a committed write of an entity
+type ChangeEvent struct {
+	EntityType string
+	EntityId   string
+	// Operation is one of the AuditOperation constants
+	Operation string
+	// Entity is a copy of the written entity, nil for deletions
+	Entity interface{}
+	// Diff holds the changed fields with flattened JSONB paths, every field for creations
+	Diff       map[string]interface{}
+	OccurredAt time.Time
+}
+
+// ChangePublisher receives the changes made through a repository once they are committed,
+// so downstream systems (Kafka, NATS, webhooks) get precise change events without
+// database-level CDC. The change is already committed when Publish is called, so
+// publishers handle their own delivery failures (retries, outbox, logging).
+type ChangePublisher interface {
+	Publish(ctx context.Context, event ChangeEvent)
+}
+
+// WithChangePublisher publishes the changes of Create, Save, DeleteById and the diff based
+// updates. Writes within WithTx are published when the transaction commits and dropped
+// when it rolls back.
+func WithChangePublisher(publisher ChangePublisher) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.changePublisher = publisher
+	}
+}
@@ -0,0 +1,88 @@
+package gormrepository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	mutex  sync.Mutex
+	events []ChangeEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event ChangeEvent) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *recordingPublisher) published() []ChangeEvent {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]ChangeEvent{}, p.events...)
+}
+
+func TestGormRepository_ChangePublisher_WithoutTransaction(t *testing.T) {
+	db := setupTestDB(t)
+	publisher := &recordingPublisher{}
+	repo := NewGormRepository[tests.TestProfile](db, WithChangePublisher(publisher))
+	ctx := context.Background()
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Bio: "Original bio"}
+	require.NoError(t, repo.Create(ctx, profile), "Create should not fail")
+
+	err := repo.UpdateInPlace(ctx, profile, func() {
+		profile.Bio = "Published bio"
+	})
+	require.NoError(t, err, "UpdateInPlace should not fail")
+	require.NoError(t, repo.DeleteById(ctx, profile.Id), "DeleteById should not fail")
+
+	events := publisher.published()
+	require.Len(t, events, 3, "Expected create, update and delete events")
+
+	require.Equal(t, AuditOperationCreate, events[0].Operation)
+	require.Equal(t, "Original bio", events[0].Diff["bio"])
+
+	require.Equal(t, AuditOperationUpdate, events[1].Operation)
+	require.Equal(t, "TestProfile", events[1].EntityType)
+	require.Equal(t, profile.Id.String(), events[1].EntityId)
+	require.Equal(t, map[string]interface{}{"bio": "Published bio"}, events[1].Diff)
+	require.Equal(t, "Published bio", events[1].Entity.(*tests.TestProfile).Bio)
+
+	require.Equal(t, AuditOperationDelete, events[2].Operation)
+	require.Nil(t, events[2].Entity, "Expected no entity for deletions")
+}
+
+func TestGormRepository_ChangePublisher_AfterCommit(t *testing.T) {
+	db := setupTestDB(t)
+	publisher := &recordingPublisher{}
+	repo := NewGormRepository[tests.TestProfile](db, WithChangePublisher(publisher))
+	ctx := context.Background()
+
+	tx := repo.BeginTransaction()
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Bio: "In transaction"}
+	require.NoError(t, repo.Create(ctx, profile, WithTx(tx)), "Create should not fail")
+
+	require.Empty(t, publisher.published(), "Expected no events before commit")
+	require.NoError(t, tx.Commit(), "Commit should not fail")
+	require.Len(t, publisher.published(), 1, "Expected event after commit")
+}
+
+func TestGormRepository_ChangePublisher_DroppedOnRollback(t *testing.T) {
+	db := setupTestDB(t)
+	publisher := &recordingPublisher{}
+	repo := NewGormRepository[tests.TestProfile](db, WithChangePublisher(publisher))
+	ctx := context.Background()
+
+	tx := repo.BeginTransaction()
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Bio: "Rolled back"}
+	require.NoError(t, repo.Create(ctx, profile, WithTx(tx)), "Create should not fail")
+	require.NoError(t, tx.Rollback(), "Rollback should not fail")
+
+	require.Empty(t, publisher.published(), "Expected no events after rollback")
+}
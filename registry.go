@@ -0,0 +1,47 @@
+package gormrepository
+
+import (
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Registry lazily creates the repositories of an application from a shared connection and
+// options, one per entity type, replacing the wiring of each repository by hand:
+//
+//	registry := gr.NewRegistry(db, gr.WithLogger(logger))
+//	users := gr.For[User](registry)
+//
+// It is safe for concurrent use.
+type Registry struct {
+	db           *gorm.DB
+	options      []RepositoryOption
+	mutex        sync.Mutex
+	repositories map[reflect.Type]interface{}
+}
+
+// NewRegistry returns a registry creating repositories on db with options
+func NewRegistry(db *gorm.DB, options ...RepositoryOption) *Registry {
+	return &Registry{
+		db:           db,
+		options:      options,
+		repositories: make(map[reflect.Type]interface{}),
+	}
+}
+
+// For returns the repository of T from registry, creating it on first use. Being generic over
+// T, it is a function rather than a method of the registry.
+func For[T any](registry *Registry) *GormRepository[T] {
+	key := reflect.TypeOf((*T)(nil)).Elem()
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if repository, ok := registry.repositories[key]; ok {
+		return repository.(*GormRepository[T])
+	}
+
+	repository := NewGormRepository[T](registry.db, registry.options...)
+	registry.repositories[key] = repository
+	return repository
+}
@@ -0,0 +1,39 @@
+package gormrepository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_For(t *testing.T) {
+	db := setupIntegrationDB(t)
+	registry := NewRegistry(db, WithManagedTimestamps())
+
+	var wg sync.WaitGroup
+	repositories := make([]*GormRepository[tests.TestSimpleEntity], 8)
+	for i := range repositories {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			repositories[i] = For[tests.TestSimpleEntity](registry)
+		}(i)
+	}
+	wg.Wait()
+	for _, repository := range repositories {
+		require.Same(t, repositories[0], repository, "Expected a single repository per type")
+	}
+	require.True(t, repositories[0].config.managedTimestamps, "Expected the registry options to apply")
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "registered"}
+	require.NoError(t, For[tests.TestSimpleEntity](registry).Create(context.Background(), entity))
+	found, err := For[tests.TestSimpleEntity](registry).FindById(context.Background(), entity.Id)
+	require.NoError(t, err)
+	require.Equal(t, "registered", found.Value)
+
+	require.NotNil(t, For[tests.TestTag](registry))
+}
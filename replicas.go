@@ -0,0 +1,70 @@
+package gormrepository
+
+import (
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+const (
+	primaryContextKey = "__primary"
+)
+
+// replicaSet holds the read replicas of a repository and picks them in turn
+type replicaSet struct {
+	pools []gorm.ConnPool
+	next  atomic.Uint64
+}
+
+// WithReplicas routes FindMany, FindPaginated, FindOne, FindById and Max to the given read
+// replicas, picked round-robin. Writes, reads within a transaction (WithTx) and reads using
+// WithPrimary stay on the primary connection. Replicas must use the same dialect as the primary.
+func WithReplicas(replicas ...*gorm.DB) RepositoryOption {
+	return func(config *repositoryConfig) {
+		if len(replicas) == 0 {
+			return
+		}
+
+		set := &replicaSet{}
+		for _, replica := range replicas {
+			set.pools = append(set.pools, replica.ConnPool)
+		}
+		config.replicas = set
+	}
+}
+
+// WithPrimary returns an option forcing a read to the primary connection, typically to read
+// back a write without replication lag
+func WithPrimary() Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(primaryContextKey, true)
+	}
+}
+
+// routeRead moves a read to a replica unless it runs in a transaction or requested the primary.
+// db must hold its own statement, as returned by WithContext.
+func (r *GormRepository[T]) routeRead(db *gorm.DB) *gorm.DB {
+	if r.config.replicas == nil {
+		return db
+	}
+	if inTransaction(db) {
+		return db
+	}
+	if primary, _ := db.Get(primaryContextKey); primary == true {
+		return db
+	}
+
+	replicas := r.config.replicas
+	index := replicas.next.Add(1) - 1
+	db.Statement.ConnPool = replicas.pools[index%uint64(len(replicas.pools))]
+	return db
+}
+
+// inTransaction reports whether db is bound to a transaction, begun by the repository or not
+func inTransaction(db *gorm.DB) bool {
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return true
+	}
+	_, inTx := db.Get(txContextKey)
+	return inTx
+}
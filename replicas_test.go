@@ -0,0 +1,59 @@
+package gormrepository
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// countingConnPool counts the queries sent through a connection pool
+type countingConnPool struct {
+	gorm.ConnPool
+	queries atomic.Int64
+}
+
+func (p *countingConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	p.queries.Add(1)
+	return p.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (p *countingConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.queries.Add(1)
+	return p.ConnPool.QueryRowContext(ctx, query, args...)
+}
+
+func TestGormRepository_WithReplicas_RoutesReads(t *testing.T) {
+	db := setupTestDB(t)
+	// The replica shares the test database, so routing is observed through its pool
+	pool := &countingConnPool{ConnPool: db.ConnPool}
+	replica := &gorm.DB{Config: &gorm.Config{ConnPool: pool}}
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithReplicas(replica))
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "replicated"}
+	require.NoError(t, repo.Create(ctx, entity), "Create should not fail")
+	require.Zero(t, pool.queries.Load(), "Expected writes to stay on the primary")
+
+	found, err := repo.FindById(ctx, entity.Id)
+	require.NoError(t, err, "FindById should not fail")
+	require.Equal(t, entity.Value, found.Value)
+	_, err = repo.FindPaginated(ctx, 1, 10)
+	require.NoError(t, err, "FindPaginated should not fail")
+	require.Equal(t, int64(3), pool.queries.Load(), "Expected reads to use the replica")
+
+	_, err = repo.FindById(ctx, entity.Id, WithPrimary())
+	require.NoError(t, err, "FindById on primary should not fail")
+
+	tx := repo.BeginTransaction()
+	_, err = repo.FindMany(ctx, WithTx(tx))
+	require.NoError(t, err, "FindMany in transaction should not fail")
+	require.NoError(t, tx.Commit())
+
+	require.Equal(t, int64(3), pool.queries.Load(), "Expected WithPrimary and WithTx reads to stay on the primary")
+}
@@ -19,6 +19,16 @@ type PaginationResult[T any] struct {
 	LastPage    int   `json:"lastPage"`
 	From        int   `json:"from"`
 	To          int   `json:"to"`
+	// Approximate is set when Total is a planner estimate, see WithEstimatedCount
+	Approximate bool `json:"approximate"`
+	// TotalUnknown is set when the count was skipped with WithoutTotal, leaving Total and
+	// LastPage unset
+	TotalUnknown    bool `json:"totalUnknown"`
+	HasNextPage     bool `json:"hasNextPage"`
+	HasPreviousPage bool `json:"hasPreviousPage"`
+	// NextPage and PreviousPage are the numbers of the adjacent pages, nil when there's none
+	NextPage     *int `json:"nextPage"`
+	PreviousPage *int `json:"previousPage"`
 }
 
 // Interface methods to avoid circular dependency with test helpers
@@ -48,6 +58,13 @@ type Diffable[T any] interface {
 	Clone() *T
 }
 
+// The repositories of the package implement Repository, which services can depend on for
+// dependency injection and mocking
+var (
+	_ Repository[struct{}] = (*GormRepository[struct{}])(nil)
+	_ Repository[struct{}] = (*MemoryRepository[struct{}])(nil)
+)
+
 type Repository[T any] interface {
 	FindMany(ctx context.Context, options ...Option) ([]*T, error)
 	FindPaginated(ctx context.Context, page int, pageSize int, options ...Option) (*PaginationResult[*T], error)
@@ -55,6 +72,7 @@ type Repository[T any] interface {
 	FindOne(ctx context.Context, options ...Option) (*T, error)
 	Max(ctx context.Context, column string, options ...Option) (int, error)
 	Create(ctx context.Context, entity *T, options ...Option) error
+	CreateMany(ctx context.Context, entities []*T, options ...Option) error
 	Save(ctx context.Context, entity *T, options ...Option) error
 	BulkUpdate(ctx context.Context, where Option, mask map[string]interface{}, options ...Option) error
 	UpdateById(ctx context.Context, id uuid.UUID, entity *T, options ...Option) error
@@ -67,5 +85,8 @@ type Repository[T any] interface {
 	AppendAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error
 	RemoveAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error
 	ReplaceAssociation(ctx context.Context, entity *T, association string, values interface{}, options ...Option) error
+	ClearAssociation(ctx context.Context, entity *T, association string, options ...Option) error
+	LoadRelation(ctx context.Context, entity *T, relations []string, options ...Option) error
+	HasAssociation(ctx context.Context, entity *T, association string, value interface{}, options ...Option) (bool, error)
 	GetDB() *gorm.DB
 }
@@ -0,0 +1,87 @@
+package gormrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Postgres error codes of transactions that can succeed when run again
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// RetryPolicy configures RunInTransactionWithRetry. Zero fields use DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the transaction is run, the first one included
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubled after every attempt
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts
+	MaxBackoff time.Duration
+	// TxOptions sets the isolation level of the transactions, the driver default when nil
+	TxOptions *sql.TxOptions
+}
+
+// DefaultRetryPolicy is used for the fields left zero in a RetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+// IsRetryableTxError reports whether err is a Postgres serialization failure (40001) or
+// deadlock (40P01), after which the whole transaction can be run again
+func IsRetryableTxError(err error) bool {
+	var state interface{ SQLState() string }
+	if !errors.As(err, &state) {
+		return false
+	}
+	code := state.SQLState()
+	return code == sqlStateSerializationFailure || code == sqlStateDeadlockDetected
+}
+
+// RunInTransactionWithRetry runs fn with RunInTransaction, running it again in a new
+// transaction with exponential backoff when it fails with a serialization failure or a
+// deadlock, as expected from concurrent updates at SERIALIZABLE isolation. fn must be safe to
+// run several times. The error of the last attempt is returned once MaxAttempts is reached.
+func (r *GormRepository[T]) RunInTransactionWithRetry(ctx context.Context, policy RetryPolicy, fn func(tx *Tx) error) error {
+	policy = policy.withDefaults()
+	backoff := policy.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := r.runInTransaction(ctx, policy.TxOptions, fn)
+		if err == nil || attempt >= policy.MaxAttempts || !IsRetryableTxError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+// withDefaults fills the zero fields of p from DefaultRetryPolicy
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	return p
+}
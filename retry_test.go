@@ -0,0 +1,60 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+// sqlStateError mimics the SQLState method of driver errors such as pgconn.PgError
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestGormRepository_RunInTransactionWithRetry(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	attempts := 0
+	err := repo.RunInTransactionWithRetry(ctx, policy, func(tx *Tx) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("update: %w", sqlStateError("40001"))
+		}
+		if attempts == 2 {
+			return sqlStateError("40P01")
+		}
+		return repo.Create(ctx, &tests.TestSimpleEntity{Value: "retried"}, WithTx(tx))
+	})
+	require.NoError(t, err, "Expected the transaction to succeed after retries")
+	require.Equal(t, 3, attempts)
+
+	var count int64
+	require.NoError(t, db.Model(&tests.TestSimpleEntity{}).Count(&count).Error)
+	require.Equal(t, int64(1), count, "Expected only the successful attempt to persist")
+
+	attempts = 0
+	err = repo.RunInTransactionWithRetry(ctx, policy, func(tx *Tx) error {
+		attempts++
+		return sqlStateError("40001")
+	})
+	require.ErrorIs(t, err, sqlStateError("40001"), "Expected the last error once attempts are exhausted")
+	require.Equal(t, 3, attempts)
+
+	attempts = 0
+	failure := errors.New("not retryable")
+	err = repo.RunInTransactionWithRetry(ctx, policy, func(tx *Tx) error {
+		attempts++
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, 1, attempts, "Expected other errors not to be retried")
+}
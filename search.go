@@ -0,0 +1,180 @@
+package gormrepository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FullTextOption configures WithFullTextSearch
+type FullTextOption func(*fullTextSearch)
+
+// FullTextDictionary sets the text search configuration, e.g. "english", used to parse the query
+// and text columns. Without it the default_text_search_config of the server applies.
+func FullTextDictionary(dictionary string) FullTextOption {
+	return func(search *fullTextSearch) {
+		search.dictionary = dictionary
+	}
+}
+
+// FullTextRanked orders the results by relevance, using ts_rank. The ranking replaces the
+// ordering set by other options.
+func FullTextRanked() FullTextOption {
+	return func(search *fullTextSearch) {
+		search.ranked = true
+	}
+}
+
+// WithFullTextSearch returns an option matching the rows whose column matches query, as parsed by
+// the Postgres plainto_tsquery: words are ANDed and punctuation is ignored, so user input can be
+// passed as is. column is a field or column name; tsvector columns are matched directly and
+// others through to_tsvector. An empty query matches every row.
+//
+//	users, err := userRepo.FindPaginated(ctx, page, size,
+//		gr.WithFullTextSearch("SearchVector", q, gr.FullTextDictionary("english"), gr.FullTextRanked()))
+func WithFullTextSearch(column string, query string, options ...FullTextOption) Option {
+	search := fullTextSearch{field: column, query: strings.TrimSpace(query)}
+	for _, option := range options {
+		option(&search)
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if search.query == "" {
+			return db
+		}
+		db = db.Where(search)
+		if search.ranked {
+			db = db.Order(clause.OrderBy{Expression: fullTextRank(search)})
+		}
+		return db
+	}
+}
+
+// fullTextSearch is a WithFullTextSearch condition. As for filterExpression, the field is resolved
+// against the schema of the statement when the SQL is built.
+type fullTextSearch struct {
+	field      string
+	query      string
+	dictionary string
+	ranked     bool
+}
+
+func (s fullTextSearch) Build(builder clause.Builder) {
+	if s.buildDocument(builder) {
+		builder.WriteString(" @@ ")
+		s.buildQuery(builder)
+	}
+}
+
+// buildDocument writes the tsvector searched, reporting whether the field was resolved
+func (s fullTextSearch) buildDocument(builder clause.Builder) bool {
+	schemaField := lookUpField(builder, s.field)
+	if schemaField == nil {
+		return false
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: schemaField.DBName}
+	if strings.EqualFold(string(schemaField.DataType), "tsvector") {
+		builder.WriteQuoted(column)
+		return true
+	}
+
+	builder.WriteString("to_tsvector(")
+	if s.dictionary != "" {
+		builder.AddVar(builder, s.dictionary)
+		builder.WriteString("::regconfig, ")
+	}
+	builder.WriteQuoted(column)
+	builder.WriteByte(')')
+	return true
+}
+
+// buildQuery writes the tsquery of the search
+func (s fullTextSearch) buildQuery(builder clause.Builder) {
+	builder.WriteString("plainto_tsquery(")
+	if s.dictionary != "" {
+		builder.AddVar(builder, s.dictionary)
+		builder.WriteString("::regconfig, ")
+	}
+	builder.AddVar(builder, s.query)
+	builder.WriteByte(')')
+}
+
+// fullTextRank orders the rows of a full text search by relevance
+type fullTextRank fullTextSearch
+
+func (r fullTextRank) Build(builder clause.Builder) {
+	search := fullTextSearch(r)
+	builder.WriteString("ts_rank(")
+	if search.buildDocument(builder) {
+		builder.WriteString(", ")
+		search.buildQuery(builder)
+		builder.WriteString(") DESC")
+	}
+}
+
+// WithSearchLike returns an option matching the rows where any of fields contains term, ignoring
+// case: ILIKE on Postgres, where a pg_trgm index can serve it, and LOWER(...) LIKE elsewhere.
+// Wildcards in term are escaped, so it matches literally. Fields are Go field or column names;
+// an unknown field fails the call with ErrInvalidFilter. An empty term matches every row.
+func WithSearchLike(fields []string, term string) Option {
+	term = strings.TrimSpace(term)
+	return func(db *gorm.DB) *gorm.DB {
+		if term == "" || len(fields) == 0 {
+			return db
+		}
+		return db.Where(likeSearch{fields: fields, pattern: "%" + escapeLike(term) + "%"})
+	}
+}
+
+// likeSearch is a WithSearchLike condition
+type likeSearch struct {
+	fields  []string
+	pattern string
+}
+
+func (s likeSearch) Build(builder clause.Builder) {
+	dialect := ""
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		dialect = stmt.Dialector.Name()
+	}
+
+	if len(s.fields) > 1 {
+		builder.WriteByte('(')
+	}
+	for i, field := range s.fields {
+		schemaField := lookUpField(builder, field)
+		if schemaField == nil {
+			return
+		}
+		if i > 0 {
+			builder.WriteString(" OR ")
+		}
+
+		column := clause.Column{Table: clause.CurrentTable, Name: schemaField.DBName}
+		if dialect == "postgres" {
+			builder.WriteQuoted(column)
+			builder.WriteString(" ILIKE ")
+			builder.AddVar(builder, s.pattern)
+		} else {
+			builder.WriteString("LOWER(")
+			builder.WriteQuoted(column)
+			builder.WriteString(") LIKE LOWER(")
+			builder.AddVar(builder, s.pattern)
+			builder.WriteByte(')')
+		}
+		// Backslash is already the default escape character of MySQL, where it must be doubled
+		if dialect != "mysql" {
+			builder.WriteString(` ESCAPE '\'`)
+		}
+	}
+	if len(s.fields) > 1 {
+		builder.WriteByte(')')
+	}
+}
+
+// escapeLike escapes the LIKE wildcards of term with backslashes
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
@@ -0,0 +1,101 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWithFullTextSearch_SQL(t *testing.T) {
+	db := setupTestDB(t).Session(&gorm.Session{DryRun: true})
+
+	stmt := WithFullTextSearch("Name", "gopher", FullTextDictionary("english"), FullTextRanked())(db.Model(&tests.TestUser{})).
+		Find(&[]tests.TestUser{}).Statement
+	require.NoError(t, stmt.Error)
+	sql := stmt.SQL.String()
+	require.Contains(t, sql, "WHERE to_tsvector(")
+	require.Contains(t, sql, "::regconfig, ")
+	require.Contains(t, sql, ") @@ plainto_tsquery(")
+	require.Contains(t, sql, "ORDER BY ts_rank(to_tsvector(")
+	require.Equal(t, []interface{}{"english", "english", "gopher", "english", "english", "gopher"}, stmt.Vars)
+
+	stmt = WithFullTextSearch("Name", "  ")(db.Model(&tests.TestUser{})).Find(&[]tests.TestUser{}).Statement
+	require.NotContains(t, stmt.SQL.String(), "WHERE", "Expected an empty query to match every row")
+}
+
+func TestGormRepository_WithFullTextSearch(t *testing.T) {
+	db := setupTestDB(t)
+	if db.Dialector.Name() != "postgres" {
+		t.Skip("full text search needs Postgres")
+	}
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	for _, user := range []*tests.TestUser{
+		{Name: "Running gopher", Email: "a@example.com"},
+		{Name: "Gopher gopher runs", Email: "b@example.com"},
+		{Name: "Sleeping cat", Email: "c@example.com"},
+	} {
+		require.NoError(t, repo.Create(ctx, user))
+	}
+
+	users, err := repo.FindMany(ctx, WithFullTextSearch("Name", "gophers run", FullTextDictionary("english"), FullTextRanked()))
+	require.NoError(t, err)
+	require.Len(t, users, 2, "Expected stemmed words to match")
+	require.Equal(t, "Gopher gopher runs", users[0].Name, "Expected the most relevant row first")
+
+	page, err := repo.FindPaginated(ctx, 1, 10, WithFullTextSearch("Name", "cat", FullTextRanked()))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), page.Total)
+
+	_, err = repo.FindMany(ctx, WithFullTextSearch("Missing", "cat"))
+	require.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestGormRepository_WithSearchLike(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	for _, user := range []*tests.TestUser{
+		{Name: "Alice Martin", Email: "alice@example.com"},
+		{Name: "Bob", Email: "bob.martin@example.com"},
+		{Name: "Carol 100%", Email: "carol@example.com"},
+		{Name: "Dave 1000", Email: "dave@example.com"},
+	} {
+		require.NoError(t, repo.Create(ctx, user))
+	}
+
+	names := func(users []*tests.TestUser) []string {
+		var names []string
+		for _, user := range users {
+			names = append(names, user.Name)
+		}
+		return names
+	}
+	byName := WithQuery(func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	})
+
+	users, err := repo.FindMany(ctx, WithSearchLike([]string{"Name", "email"}, "MARTIN"), byName)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice Martin", "Bob"}, names(users), "Expected a case-insensitive match on any field")
+
+	users, err = repo.FindMany(ctx, WithSearchLike([]string{"Name"}, "100%"), byName)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Carol 100%"}, names(users), "Expected wildcards to match literally")
+
+	page, err := repo.FindPaginated(ctx, 1, 10, WithSearchLike([]string{"Name"}, " "))
+	require.NoError(t, err)
+	require.Equal(t, int64(4), page.Total, "Expected an empty term to match every row")
+
+	_, err = repo.FindMany(ctx, WithSearchLike([]string{"Missing"}, "a"))
+	require.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestEscapeLike(t *testing.T) {
+	require.Equal(t, `50\% off\_now \\o/`, escapeLike(`50% off_now \o/`))
+}
@@ -0,0 +1,120 @@
+package gormrepository
+
+import (
+	"container/list"
+
+	"gorm.io/gorm"
+)
+
+const (
+	noSnapshotContextKey = "__no_snapshot"
+)
+
+// WithSnapshotLimit caps the number of entity snapshots a transaction of the repository keeps,
+// evicting the least recently used ones beyond limit. Updates of an evicted entity diff against
+// a blank entity, as outside of a transaction: every non-zero field is written. 0 keeps all
+// snapshots, the default.
+func WithSnapshotLimit(limit int) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.snapshotLimit = limit
+	}
+}
+
+// WithNoSnapshot returns an option skipping the snapshot of the entities read or written within
+// a transaction, saving their Clone for paths that never update them. It must follow WithTx.
+// Updates of these entities diff against a blank entity.
+func WithNoSnapshot() Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(noSnapshotContextKey, true)
+	}
+}
+
+// snapshotStore holds the entity snapshots of a transaction by entity key, evicting the least
+// recently used one once it holds limit snapshots. It isn't safe for concurrent use.
+type snapshotStore struct {
+	limit   int
+	entries map[string]*list.Element
+	// order holds the snapshotEntry values, most recently used first
+	order *list.List
+}
+
+type snapshotEntry struct {
+	key   string
+	clone interface{}
+}
+
+func newSnapshotStore(limit int) *snapshotStore {
+	return &snapshotStore{
+		limit:   limit,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the snapshot stored for key and marks it as recently used
+func (s *snapshotStore) get(key string) (interface{}, bool) {
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*snapshotEntry).clone, true
+}
+
+// put stores clone for key, evicting the least recently used snapshot when full
+func (s *snapshotStore) put(key string, clone interface{}) {
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*snapshotEntry).clone = clone
+		s.order.MoveToFront(element)
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(&snapshotEntry{key: key, clone: clone})
+	if s.limit > 0 && s.order.Len() > s.limit {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*snapshotEntry).key)
+	}
+}
+
+// remove drops the snapshot stored for key, if any
+func (s *snapshotStore) remove(key string) {
+	if element, ok := s.entries[key]; ok {
+		s.order.Remove(element)
+		delete(s.entries, key)
+	}
+}
+
+// len returns the number of stored snapshots
+func (s *snapshotStore) len() int {
+	return s.order.Len()
+}
+
+// clear removes all snapshots
+func (s *snapshotStore) clear() {
+	s.entries = make(map[string]*list.Element)
+	s.order.Init()
+}
+
+// copy returns a store holding the snapshots of s in the same order
+func (s *snapshotStore) copy() *snapshotStore {
+	copied := newSnapshotStore(s.limit)
+	copied.merge(s)
+	return copied
+}
+
+// merge stores the snapshots of other in s, keeping their recency order
+func (s *snapshotStore) merge(other *snapshotStore) {
+	for element := other.order.Back(); element != nil; element = element.Prev() {
+		entry := element.Value.(*snapshotEntry)
+		s.put(entry.key, entry.clone)
+	}
+}
+
+// ClearSnapshots drops the entity snapshots taken by the transaction so far, e.g. between the
+// chunks of a batch job. Later updates of these entities diff against a blank entity.
+func (tx *Tx) ClearSnapshots() {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.clonedEntities.clear()
+}
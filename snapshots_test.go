@@ -0,0 +1,143 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+// testMembership has a composite primary key following the ID naming convention
+type testMembership struct {
+	OrgID  uuid.UUID `gorm:"type:text;primaryKey"`
+	UserID uuid.UUID `gorm:"type:text;primaryKey"`
+	Role   string
+}
+
+func (m *testMembership) Clone() *testMembership {
+	clone := *m
+	return &clone
+}
+
+func (m *testMembership) Diff(old *testMembership) map[string]interface{} {
+	diff := make(map[string]interface{})
+	if m.Role != old.Role {
+		diff["role"] = m.Role
+	}
+	return diff
+}
+
+func TestGenerateEntityKey_PrimaryKeys(t *testing.T) {
+	db := setupTestDB(t)
+
+	orgId, userId := uuid.New(), uuid.New()
+	membership := &testMembership{OrgID: orgId, UserID: userId}
+	require.Equal(t, "testMembership_"+orgId.String()+"_"+userId.String(), generateEntityKey(db, membership))
+	require.Equal(t, generateEntityKey(db, membership), generateEntityKey(db, membership.Clone()), "Expected copies to share their key")
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New()}
+	require.Equal(t, "TestSimpleEntity_"+entity.Id.String(), generateEntityKey(db, entity))
+	require.Equal(t, "TestSimpleEntity@tenant_entities_"+entity.Id.String(), generateEntityKey(WithTable("tenant_entities")(db), entity), "Expected overridden tables to be part of the key")
+}
+
+func TestGormRepository_Snapshots_CompositeKey(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[testMembership](db)
+
+	tx := repo.BeginTransaction()
+	defer tx.Rollback()
+
+	membership := &testMembership{OrgID: uuid.New(), UserID: uuid.New(), Role: "member"}
+	storeCloneIfInTransaction(WithTx(tx)(db), membership)
+
+	copied := membership.Clone()
+	copied.Role = "owner"
+	clone, isSnapshot := getCloneForDiff(WithTx(tx)(db), copied)
+	require.True(t, isSnapshot, "Expected snapshots to be matched by primary key")
+	require.Equal(t, map[string]interface{}{"role": "owner"}, copied.Diff(clone))
+}
+
+func TestSnapshotStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := newSnapshotStore(2)
+	store.put("a", 1)
+	store.put("b", 2)
+
+	_, found := store.get("a")
+	require.True(t, found)
+
+	store.put("c", 3)
+	require.Equal(t, 2, store.len())
+	_, found = store.get("b")
+	require.False(t, found, "Expected the least recently used snapshot to be evicted")
+
+	copied := store.copy()
+	copied.put("d", 4)
+	_, found = copied.get("a")
+	require.False(t, found, "Expected copies to keep the recency order")
+	_, found = store.get("a")
+	require.True(t, found, "Expected copies to be independent")
+
+	store.clear()
+	require.Zero(t, store.len())
+}
+
+func TestGormRepository_WithSnapshotLimit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithSnapshotLimit(2))
+	ctx := context.Background()
+
+	var entities []*tests.TestSimpleEntity
+	for _, value := range []string{"first", "second", "third"} {
+		entity := &tests.TestSimpleEntity{Value: value}
+		require.NoError(t, repo.Create(ctx, entity))
+		entities = append(entities, entity)
+	}
+
+	tx := repo.BeginTransaction()
+	defer tx.Rollback()
+
+	var found []*tests.TestSimpleEntity
+	for _, entity := range entities {
+		entity, err := repo.FindById(ctx, entity.Id, WithTx(tx))
+		require.NoError(t, err)
+		found = append(found, entity)
+	}
+
+	_, isSnapshot := getCloneForDiff(WithTx(tx)(db), found[0])
+	require.False(t, isSnapshot, "Expected the first snapshot to be evicted")
+	_, isSnapshot = getCloneForDiff(WithTx(tx)(db), found[2])
+	require.True(t, isSnapshot)
+
+	found[0].Value = "updated after eviction"
+	require.NoError(t, repo.UpdateById(ctx, found[0].Id, found[0], WithTx(tx)), "Evicted entities should still update")
+
+	tx.ClearSnapshots()
+	_, isSnapshot = getCloneForDiff(WithTx(tx)(db), found[2])
+	require.False(t, isSnapshot, "Expected ClearSnapshots to drop all snapshots")
+}
+
+func TestGormRepository_WithNoSnapshot(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Value: "original"}
+	require.NoError(t, repo.Create(ctx, entity))
+
+	tx := repo.BeginTransaction()
+	defer tx.Rollback()
+
+	found, err := repo.FindById(ctx, entity.Id, WithTx(tx), WithNoSnapshot())
+	require.NoError(t, err)
+	require.Zero(t, tx.clonedEntities.len(), "Expected no snapshot with WithNoSnapshot")
+
+	found, err = repo.FindById(ctx, found.Id, WithTx(tx))
+	require.NoError(t, err)
+	require.Equal(t, 1, tx.clonedEntities.len())
+
+	err = repo.UpdateByIdInPlace(ctx, found.Id, found, func() { found.Value = "updated" }, WithTx(tx), WithNoSnapshot())
+	require.NoError(t, err)
+	require.Zero(t, tx.clonedEntities.len(), "Expected writes with WithNoSnapshot to drop the outdated snapshot")
+}
@@ -0,0 +1,58 @@
+package gormrepository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidSort is returned by the calls using WithSortFromRequest with a field that isn't allowed
+var ErrInvalidSort = errors.New("invalid sort")
+
+// WithSortFromRequest returns an option ordering by a sort parameter as received from a request,
+// e.g. "name,-createdAt": fields are separated by commas and sorted descending when prefixed
+// with "-". allowed maps the accepted fields to their columns; any other field fails the call
+// with ErrInvalidSort. An empty parameter leaves the order unchanged.
+func WithSortFromRequest(sortParam string, allowed map[string]string) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		columns, err := parseSort(sortParam, allowed)
+		if err != nil {
+			db = db.Session(&gorm.Session{})
+			db.AddError(err)
+			return db
+		}
+		for _, column := range columns {
+			db = db.Order(column)
+		}
+		return db
+	}
+}
+
+// parseSort converts a sort parameter into ORDER BY columns
+func parseSort(sortParam string, allowed map[string]string) ([]clause.OrderByColumn, error) {
+	var columns []clause.OrderByColumn
+	for _, field := range strings.Split(sortParam, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		desc := false
+		switch field[0] {
+		case '-':
+			desc, field = true, field[1:]
+		case '+':
+			field = field[1:]
+		}
+
+		column, ok := allowed[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSort, field)
+		}
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+	}
+	return columns, nil
+}
@@ -0,0 +1,40 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_WithSortFromRequest(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	for _, user := range []*tests.TestUser{
+		{Name: "Bob", Email: "bob@example.com", Age: 30},
+		{Name: "Alice", Email: "alice@example.com", Age: 30},
+		{Name: "Carol", Email: "carol@example.com", Age: 25},
+	} {
+		require.NoError(t, repo.Create(ctx, user))
+	}
+
+	allowed := map[string]string{"name": "name", "age": "age"}
+	users, err := repo.FindMany(ctx, WithSortFromRequest("-age, name", allowed))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice", "Bob", "Carol"}, []string{users[0].Name, users[1].Name, users[2].Name})
+
+	page, err := repo.FindPaginated(ctx, 1, 2, WithSortFromRequest("+name", allowed))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), page.Total)
+	require.Equal(t, "Alice", page.Data[0].Name)
+
+	_, err = repo.FindMany(ctx, WithSortFromRequest("name;DROP TABLE test_users", allowed))
+	require.ErrorIs(t, err, ErrInvalidSort)
+
+	users, err = repo.FindMany(ctx, WithSortFromRequest("", allowed))
+	require.NoError(t, err, "Expected the repository to be usable after an invalid sort")
+	require.Len(t, users, 3)
+}
@@ -0,0 +1,104 @@
+package gormrepository
+
+import (
+	"gorm.io/gorm"
+)
+
+// Specification is a reusable query rule of the domain, e.g. "active adult users", applied to
+// repository calls with WithSpecification and combined with And, Or and Not. Specifications
+// only depend on *gorm.DB, so they can be unit tested without a repository.
+type Specification interface {
+	ToQuery(db *gorm.DB) *gorm.DB
+}
+
+// SpecificationFunc adapts a function to the Specification interface
+type SpecificationFunc func(db *gorm.DB) *gorm.DB
+
+func (f SpecificationFunc) ToQuery(db *gorm.DB) *gorm.DB {
+	return f(db)
+}
+
+// WithSpecification returns an option applying spec to the query
+func WithSpecification(spec Specification) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return spec.ToQuery(db)
+	}
+}
+
+// And returns a specification matched when all specs are
+func And(specs ...Specification) Specification {
+	return SpecificationFunc(func(db *gorm.DB) *gorm.DB {
+		for _, spec := range specs {
+			db = spec.ToQuery(db)
+		}
+		return db
+	})
+}
+
+// Or returns a specification matched when any of specs is. The conditions of each spec are
+// grouped in parentheses; only their WHERE conditions are kept, not joins or ordering.
+func Or(specs ...Specification) Specification {
+	return SpecificationFunc(func(db *gorm.DB) *gorm.DB {
+		if len(specs) == 0 {
+			return db
+		}
+
+		group := db.Session(&gorm.Session{NewDB: true})
+		for i, spec := range specs {
+			conditions := spec.ToQuery(db.Session(&gorm.Session{NewDB: true}))
+			db = withGroupError(db, conditions)
+			if i == 0 {
+				group = group.Where(conditions)
+			} else {
+				group = group.Or(conditions)
+			}
+		}
+		return db.Where(group)
+	})
+}
+
+// Not returns a specification matched when spec isn't. As with Or, only the WHERE conditions
+// of spec are kept.
+func Not(spec Specification) Specification {
+	return SpecificationFunc(func(db *gorm.DB) *gorm.DB {
+		conditions := spec.ToQuery(db.Session(&gorm.Session{NewDB: true}))
+		return withGroupError(db, conditions).Not(conditions)
+	})
+}
+
+// withGroupError adds the error of group, conditions built in a new session, to db: Where
+// only keeps the conditions of group, so a rejected filter would otherwise match every row
+func withGroupError(db *gorm.DB, group *gorm.DB) *gorm.DB {
+	if group.Error == nil || db.Error != nil {
+		return db
+	}
+	db = db.Session(&gorm.Session{})
+	db.AddError(group.Error)
+	return db
+}
+
+// WithAnd returns an option matching the rows matched by all options, their WHERE conditions
+// grouped in parentheses. Other clauses set by the options, e.g. ordering, are dropped.
+func WithAnd(options ...Option) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(options) == 0 {
+			return db
+		}
+		group := db.Session(&gorm.Session{NewDB: true})
+		for _, option := range options {
+			group = option(group)
+		}
+		return withGroupError(db, group).Where(group)
+	}
+}
+
+// WithOr returns an option matching the rows matched by any of options, each grouped in
+// parentheses: WithOr(WithAnd(a, b), WithAnd(c, d)) reads (a AND b) OR (c AND d). As with
+// WithAnd, only WHERE conditions are kept.
+func WithOr(options ...Option) Option {
+	specs := make([]Specification, len(options))
+	for i, option := range options {
+		specs[i] = SpecificationFunc(option)
+	}
+	return WithSpecification(Or(specs...))
+}
@@ -0,0 +1,100 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormRepository_WithSpecification(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	for _, user := range []*tests.TestUser{
+		{Name: "Alice", Email: "alice@example.com", Age: 17, Active: true},
+		{Name: "Bob", Email: "bob@example.com", Age: 30, Active: true},
+		{Name: "Carol", Email: "carol@example.com", Age: 45},
+	} {
+		require.NoError(t, repo.Create(ctx, user))
+	}
+
+	adult := SpecificationFunc(func(db *gorm.DB) *gorm.DB { return db.Where("age >= ?", 18) })
+	active := SpecificationFunc(func(db *gorm.DB) *gorm.DB { return db.Where("active = ?", true) })
+	byName := WithQuery(func(db *gorm.DB) *gorm.DB { return db.Order("name") })
+
+	names := func(spec Specification) []string {
+		users, err := repo.FindMany(ctx, WithSpecification(spec), byName)
+		require.NoError(t, err)
+		var names []string
+		for _, user := range users {
+			names = append(names, user.Name)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{"Bob"}, names(And(adult, active)))
+	require.Equal(t, []string{"Alice", "Bob", "Carol"}, names(Or(adult, active)))
+	require.Equal(t, []string{"Alice"}, names(Not(adult)))
+	require.Equal(t, []string{"Alice", "Carol"}, names(Or(And(active, Not(adult)), And(adult, Not(active)))))
+}
+
+func TestGormRepository_WithOr(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestUser](db)
+	ctx := context.Background()
+
+	for _, user := range []*tests.TestUser{
+		{Name: "Alice", Email: "alice@example.com", Age: 17, Active: true},
+		{Name: "Bob", Email: "bob@example.com", Age: 30, Active: true},
+		{Name: "Carol", Email: "carol@example.com", Age: 45},
+		{Name: "Dave", Email: "dave@example.com", Age: 15},
+	} {
+		require.NoError(t, repo.Create(ctx, user))
+	}
+
+	where := func(query string, args ...interface{}) Option {
+		return WithQuery(func(db *gorm.DB) *gorm.DB { return db.Where(query, args...) })
+	}
+
+	users, err := repo.FindMany(ctx,
+		WithOr(
+			WithAnd(where("age >= ?", 18), where("active = ?", false)),
+			WithAnd(where("age < ?", 18), where("active = ?", true)),
+		),
+		where("name <> ?", "Bob"),
+		WithQuery(func(db *gorm.DB) *gorm.DB { return db.Order("name") }),
+	)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Equal(t, "Alice", users[0].Name)
+	require.Equal(t, "Carol", users[1].Name)
+
+	users, err = repo.FindMany(ctx, where("name = ?", "Dave"), WithAnd(WithOr(where("age > ?", 40), where("active = ?", true))))
+	require.NoError(t, err)
+	require.Empty(t, users, "Expected the group to bind tighter than the preceding condition")
+}
+
+func TestWithOr_RejectedFilter(t *testing.T) {
+	db := dryRunPostgres(t)
+	rejected := WithFilters(map[string]interface{}{"Secret": 1}, []string{"Name"})
+
+	for name, option := range map[string]Option{
+		"or":  WithOr(rejected),
+		"and": WithAnd(rejected),
+		"not": WithSpecification(Not(SpecificationFunc(rejected))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var users []tests.TestUser
+			err := option(db.Model(&tests.TestUser{})).Find(&users).Error
+			require.ErrorIs(t, err, ErrInvalidFilter, "Expected the rejected filter to fail the call")
+		})
+	}
+
+	repo := NewGormRepository[tests.TestUser](setupTestDB(t))
+	_, err := repo.FindMany(context.Background(), WithOr(rejected, WithQuery(func(db *gorm.DB) *gorm.DB { return db.Where("age > ?", 18) })))
+	require.ErrorIs(t, err, ErrInvalidFilter)
+}
@@ -0,0 +1,105 @@
+package gormrepository
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SQLTag is a key/value pair added to the SQL comment of repository queries, its value read
+// from the context of the call. Tags with an empty value are left out.
+type SQLTag struct {
+	Key   string
+	Value func(ctx context.Context) string
+}
+
+// sqlCommentConfig holds the tags set with WithSQLComments
+type sqlCommentConfig struct {
+	app  string
+	tags []SQLTag
+}
+
+// WithSQLComments prefixes the SQL generated by the repository methods with a comment such as
+// /* app=billing, op=FindMany, entity=User, request_id=42 */, so slow queries reported by
+// pg_stat_statements or the database logs can be attributed to code paths. app is left out
+// when empty; tags add values read from the context of the call, like the request id.
+// Statements whose leading clause is built by the dialect itself, like INSERT with SQLite,
+// are left untagged.
+func WithSQLComments(app string, tags ...SQLTag) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.sqlComments = &sqlCommentConfig{app: app, tags: tags}
+	}
+}
+
+// sqlComment is the leading comment of a statement
+type sqlComment string
+
+// Build writes the comment
+func (c sqlComment) Build(builder clause.Builder) {
+	builder.WriteString("/* ")
+	builder.WriteString(string(c))
+	builder.WriteString(" */")
+}
+
+// commentClauses are the leading clauses of the statements built by the repository methods
+var commentClauses = []string{"SELECT", "INSERT", "UPDATE", "DELETE"}
+
+// tagStatement adds the SQL comment of the call to the statement of db. db must hold its own
+// statement, as returned by WithContext.
+func (r *GormRepository[T]) tagStatement(ctx context.Context, db *gorm.DB) {
+	if r.config.sqlComments == nil {
+		return
+	}
+
+	comment := r.config.sqlComments.comment(ctx)
+	if comment == "" {
+		return
+	}
+
+	for _, name := range commentClauses {
+		c := db.Statement.Clauses[name]
+		if hints, ok := c.BeforeExpression.(optimizerHints); ok {
+			// pg_hint_plan only reads the leading comment
+			c.BeforeExpression = clause.Expr{SQL: "? ?", Vars: []interface{}{hints, comment}}
+		} else {
+			c.BeforeExpression = comment
+		}
+		db.Statement.Clauses[name] = c
+	}
+}
+
+// comment renders the comment of a call, from its operation and the configured tags
+func (c *sqlCommentConfig) comment(ctx context.Context) sqlComment {
+	var pairs []string
+	add := func(key, value string) {
+		if value != "" {
+			pairs = append(pairs, sanitizeSQLComment(key)+"="+sanitizeSQLComment(value))
+		}
+	}
+
+	add("app", c.app)
+	if op, ok := OperationFromContext(ctx); ok {
+		add("op", op.Method)
+		add("entity", op.EntityType)
+	}
+	for _, tag := range c.tags {
+		add(tag.Key, tag.Value(ctx))
+	}
+
+	return sqlComment(strings.Join(pairs, ", "))
+}
+
+// sanitizeSQLComment makes a tag part safe to embed in a comment. Every * and / is dropped,
+// not only the */ and /* sequences, as removing a sequence can join its neighbours into a new
+// one ("**//"). NUL bytes and ?, read as a parameter placeholder, are dropped too.
+func sanitizeSQLComment(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '*', '/', '\x00', '?':
+			return -1
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,73 @@
+package gormrepository
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type requestIdContextKey struct{}
+
+// sqlRecorder is a GORM logger keeping the executed SQL
+type sqlRecorder struct {
+	logger.Interface
+	mutex      sync.Mutex
+	statements []string
+}
+
+func (l *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	sql, _ := fc()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.statements = append(l.statements, sql)
+}
+
+func TestGormRepository_WithSQLComments(t *testing.T) {
+	db := setupTestDB(t)
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	requestId := SQLTag{Key: "request_id", Value: func(ctx context.Context) string {
+		id, _ := ctx.Value(requestIdContextKey{}).(string)
+		return id
+	}}
+	repo := NewGormRepository[tests.TestSimpleEntity](db.Session(&gorm.Session{Logger: recorder}), WithSQLComments("billing", requestId))
+	ctx := context.WithValue(context.Background(), requestIdContextKey{}, "req-*/42")
+
+	// Created without the repository: SQLite builds INSERT itself, without the comment
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "tagged"}
+	require.NoError(t, db.Create(entity).Error)
+
+	_, err := repo.FindPaginated(ctx, 1, 10)
+	require.NoError(t, err, "FindPaginated should not fail")
+	require.NoError(t, repo.UpdateById(ctx, entity.Id, &tests.TestSimpleEntity{Value: "updated"}), "UpdateById should not fail")
+	require.NoError(t, repo.DeleteById(context.Background(), entity.Id), "DeleteById should not fail")
+
+	require.Len(t, recorder.statements, 4)
+	require.True(t, strings.HasPrefix(recorder.statements[0], "/* app=billing, op=FindPaginated, entity=TestSimpleEntity, request_id=req-42 */ SELECT count(*)"), recorder.statements[0])
+	require.True(t, strings.HasPrefix(recorder.statements[1], "/* app=billing, op=FindPaginated, entity=TestSimpleEntity, request_id=req-42 */ SELECT"), recorder.statements[1])
+	require.True(t, strings.HasPrefix(recorder.statements[2], "/* app=billing, op=UpdateById, entity=TestSimpleEntity, request_id=req-42 */ UPDATE"), recorder.statements[2])
+	require.True(t, strings.HasPrefix(recorder.statements[3], "/* app=billing, op=DeleteById, entity=TestSimpleEntity */ DELETE"), recorder.statements[3])
+}
+
+func TestGormRepository_WithSQLComments_ClosingSequence(t *testing.T) {
+	requestId := SQLTag{Key: "request_id", Value: func(ctx context.Context) string {
+		id, _ := ctx.Value(requestIdContextKey{}).(string)
+		return id
+	}}
+	repo := NewGormRepository[tests.TestUser](dryRunPostgres(t), WithSQLComments("billing", requestId))
+
+	for _, value := range []string{"**//", "x*?/; DROP TABLE t; --"} {
+		ctx := context.WithValue(context.Background(), requestIdContextKey{}, value)
+		db, cancel := repo.session(ctx, nil)
+		stmt := db.Find(&[]tests.TestUser{}).Statement
+		cancel()
+		require.Equal(t, 1, strings.Count(stmt.SQL.String(), "*/"), "Expected %q not to close the comment: %s", value, stmt.SQL.String())
+	}
+}
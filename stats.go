@@ -0,0 +1,85 @@
+package gormrepository
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OperationStats holds the statistics of one repository method
+type OperationStats struct {
+	// Count is the number of calls
+	Count int64
+	// Errors is the number of calls that returned an error
+	Errors int64
+	// TotalDuration is the cumulative latency of the calls
+	TotalDuration time.Duration
+}
+
+// AverageDuration returns the mean latency of the calls
+func (s OperationStats) AverageDuration() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Count)
+}
+
+// RepositoryStats is a snapshot of the statistics of a repository, keyed by method name
+type RepositoryStats struct {
+	EntityType string
+	Operations map[string]OperationStats
+}
+
+// operationCounters accumulates the statistics of one method
+type operationCounters struct {
+	count    atomic.Int64
+	errors   atomic.Int64
+	duration atomic.Int64
+}
+
+// operationStatsRecorder accumulates the statistics of every method of a repository.
+// The zero value is ready to use.
+type operationStatsRecorder struct {
+	counters sync.Map
+}
+
+// record adds a call of method to the statistics
+func (s *operationStatsRecorder) record(method string, duration time.Duration, err error) {
+	value, ok := s.counters.Load(method)
+	if !ok {
+		value, _ = s.counters.LoadOrStore(method, &operationCounters{})
+	}
+
+	counters := value.(*operationCounters)
+	counters.count.Add(1)
+	counters.duration.Add(int64(duration))
+	if err != nil {
+		counters.errors.Add(1)
+	}
+}
+
+// Stats returns the number of calls, errors and cumulative latency of every method called on
+// the repository since it was created or ResetStats was called
+func (r *GormRepository[T]) Stats() RepositoryStats {
+	stats := RepositoryStats{
+		EntityType: entityTypeName[T](),
+		Operations: make(map[string]OperationStats),
+	}
+
+	r.stats.counters.Range(func(key, value interface{}) bool {
+		counters := value.(*operationCounters)
+		stats.Operations[key.(string)] = OperationStats{
+			Count:         counters.count.Load(),
+			Errors:        counters.errors.Load(),
+			TotalDuration: time.Duration(counters.duration.Load()),
+		}
+		return true
+	})
+
+	return stats
+}
+
+// ResetStats clears the statistics returned by Stats
+func (r *GormRepository[T]) ResetStats() {
+	r.stats.counters.Clear()
+}
@@ -0,0 +1,35 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_Stats(t *testing.T) {
+	db := setupTestDB(t)
+	repo := &GormRepository[tests.TestSimpleEntity]{DB: db}
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Value: "counted"}
+	require.NoError(t, repo.Create(ctx, entity), "Create should not fail")
+	_, err := repo.FindById(ctx, entity.Id)
+	require.NoError(t, err, "FindById should not fail")
+	_, err = repo.FindById(ctx, uuid.New())
+	require.Error(t, err)
+
+	stats := repo.Stats()
+	require.Equal(t, "TestSimpleEntity", stats.EntityType)
+	require.Len(t, stats.Operations, 2)
+	require.Equal(t, int64(1), stats.Operations["Create"].Count)
+	require.Equal(t, int64(2), stats.Operations["FindById"].Count)
+	require.Equal(t, int64(1), stats.Operations["FindById"].Errors)
+	require.Positive(t, stats.Operations["FindById"].TotalDuration)
+	require.Equal(t, stats.Operations["FindById"].TotalDuration/2, stats.Operations["FindById"].AverageDuration())
+
+	repo.ResetStats()
+	require.Empty(t, repo.Stats().Operations)
+}
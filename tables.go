@@ -0,0 +1,94 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidTableName is returned by the calls using WithTable with a name that isn't a plain,
+// optionally schema qualified, identifier
+var ErrInvalidTableName = errors.New("invalid table name")
+
+// tableNamePattern matches the table names accepted by WithTable, e.g. events_2024_06 or
+// tenant_42.orders
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// WithTable returns an option running the call against table instead of the table of the
+// entity, for per-tenant or per-period tables sharing its structure. Conditions, diffs and
+// JSONB updates target the overridden table. Names are validated rather than escaped, as they
+// often derive from request data; invalid names fail the call with ErrInvalidTableName.
+func WithTable(table string) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		if !tableNamePattern.MatchString(table) {
+			db = db.Session(&gorm.Session{})
+			db.AddError(fmt.Errorf("%w: %q", ErrInvalidTableName, table))
+			return db
+		}
+		return db.Table(table)
+	}
+}
+
+// TableRouter picks the table of a repository call, for tables sharded by tenant or period.
+// entity is the entity written, or nil for the calls without one, like reads and DeleteById;
+// the method is available through OperationFromContext. An empty name uses the table of the
+// entity, typically the parent of Postgres partitions.
+type TableRouter func(ctx context.Context, entity interface{}) string
+
+// WithTableRouter makes the repository methods run against the table picked by router. Calls
+// using WithTable keep their table, and association methods the tables of the relationship,
+// as GORM would apply the table of the entity to the related rows. The names returned are
+// validated as with WithTable.
+func WithTableRouter(router TableRouter) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.tableRouter = router
+	}
+}
+
+// routeTable sets the table picked by the TableRouter for entity on db, unless the call uses
+// WithTable. As the db returned by session, the routed db can be reused for several queries.
+func (r *GormRepository[T]) routeTable(ctx context.Context, db *gorm.DB, entity *T) *gorm.DB {
+	if r.config.tableRouter == nil || db.Statement.Table != "" {
+		return db
+	}
+
+	var value interface{}
+	if entity != nil {
+		value = entity
+	}
+	table := r.config.tableRouter(ctx, value)
+	if table == "" {
+		return db
+	}
+	return WithTable(table)(db).Session(&gorm.Session{})
+}
+
+// tableBatch is a group of entities written to the same table
+type tableBatch[T any] struct {
+	db       *gorm.DB
+	entities []*T
+}
+
+// routeBatch groups entities by the table picked by the TableRouter, in order of appearance
+func (r *GormRepository[T]) routeBatch(ctx context.Context, db *gorm.DB, entities []*T) []tableBatch[T] {
+	if r.config.tableRouter == nil || db.Statement.Table != "" {
+		return []tableBatch[T]{{db: db, entities: entities}}
+	}
+
+	var batches []tableBatch[T]
+	index := make(map[string]int)
+	for _, entity := range entities {
+		routed := r.routeTable(ctx, db, entity)
+		i, ok := index[routed.Statement.Table]
+		if !ok {
+			i = len(batches)
+			index[routed.Statement.Table] = i
+			batches = append(batches, tableBatch[T]{db: routed})
+		}
+		batches[i].entities = append(batches[i].entities, entity)
+	}
+	return batches
+}
@@ -0,0 +1,126 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_WithTable(t *testing.T) {
+	db := setupTestDB(t)
+	const table = "test_simple_entities_2024_06"
+	require.NoError(t, db.Table(table).AutoMigrate(&tests.TestSimpleEntity{}))
+	t.Cleanup(func() {
+		_ = db.Migrator().DropTable(table)
+	})
+
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "june"}
+	require.NoError(t, repo.Create(ctx, entity, WithTable(table)))
+
+	_, err := repo.FindById(ctx, entity.Id)
+	require.ErrorIs(t, err, ErrNotFound, "Expected the entity table to be left untouched")
+
+	tx := repo.BeginTransaction()
+	found, err := repo.FindById(ctx, entity.Id, WithTx(tx), WithTable(table))
+	require.NoError(t, err)
+	found.Value = "updated"
+	require.NoError(t, repo.UpdateById(ctx, entity.Id, found, WithTx(tx), WithTable(table)))
+	require.NoError(t, tx.Commit())
+
+	page, err := repo.FindPaginated(ctx, 1, 10, WithTable(table))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), page.Total)
+	require.Equal(t, "updated", page.Data[0].Value)
+
+	require.NoError(t, repo.DeleteById(ctx, entity.Id, WithTable(table)))
+	entities, err := repo.FindMany(ctx, WithTable(table))
+	require.NoError(t, err)
+	require.Empty(t, entities)
+
+	_, err = repo.FindMany(ctx, WithTable("events; DROP TABLE test_users"))
+	require.ErrorIs(t, err, ErrInvalidTableName)
+}
+
+func TestGormRepository_WithTableRouter(t *testing.T) {
+	db := setupTestDB(t)
+	tables := []string{"test_simple_entities_a", "test_simple_entities_b"}
+	for _, table := range tables {
+		require.NoError(t, db.Table(table).AutoMigrate(&tests.TestSimpleEntity{}))
+	}
+	t.Cleanup(func() {
+		for _, table := range tables {
+			_ = db.Migrator().DropTable(table)
+		}
+	})
+
+	type shardKey struct{}
+	var methods []string
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithTableRouter(func(ctx context.Context, entity interface{}) string {
+		op, _ := OperationFromContext(ctx)
+		methods = append(methods, op.Method)
+		if entity, ok := entity.(*tests.TestSimpleEntity); ok {
+			return "test_simple_entities_" + entity.Value[:1]
+		}
+		shard, _ := ctx.Value(shardKey{}).(string)
+		if shard == "" {
+			return ""
+		}
+		return "test_simple_entities_" + shard
+	}))
+	ctx := context.Background()
+	shardA := context.WithValue(ctx, shardKey{}, "a")
+	shardB := context.WithValue(ctx, shardKey{}, "b")
+
+	require.NoError(t, repo.CreateMany(ctx, []*tests.TestSimpleEntity{
+		{Id: uuid.New(), Value: "a1"},
+		{Id: uuid.New(), Value: "b1"},
+		{Id: uuid.New(), Value: "a2"},
+	}))
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "b2"}
+	require.NoError(t, repo.Create(ctx, entity))
+
+	entities, err := repo.FindMany(shardA)
+	require.NoError(t, err)
+	require.Len(t, entities, 2)
+	entities, err = repo.FindMany(shardB)
+	require.NoError(t, err)
+	require.Len(t, entities, 2)
+	entities, err = repo.FindMany(ctx)
+	require.NoError(t, err)
+	require.Empty(t, entities, "Expected an empty table name to use the table of the entity")
+
+	entities, err = repo.FindMany(shardB, WithTable("test_simple_entities_a"))
+	require.NoError(t, err)
+	require.Len(t, entities, 2, "Expected WithTable to take precedence")
+
+	require.NoError(t, repo.UpdateInPlace(ctx, entity, func() { entity.Value = "b3" }))
+	found, err := repo.FindById(shardB, entity.Id)
+	require.NoError(t, err)
+	require.Equal(t, "b3", found.Value)
+
+	require.NoError(t, repo.DeleteById(shardB, entity.Id))
+	_, err = repo.FindById(shardB, entity.Id)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Contains(t, methods, "UpdateInPlace")
+}
+
+func TestGormRepository_RouteTable_Reusable(t *testing.T) {
+	repo := NewGormRepository[tests.TestUser](dryRunPostgres(t), WithTableRouter(func(ctx context.Context, entity interface{}) string {
+		return "tenant_users"
+	}))
+	ctx := context.Background()
+
+	db, cancel := repo.session(ctx, nil)
+	defer cancel()
+	routed := repo.routeTable(ctx, db, nil)
+	stmt := routed.Where("age > ?", 18).Find(&[]tests.TestUser{}).Statement
+	require.Equal(t, `SELECT * FROM "tenant_users" WHERE age > $1`, stmt.SQL.String())
+	stmt = routed.Find(&[]tests.TestUser{}).Statement
+	require.Equal(t, `SELECT * FROM "tenant_users"`, stmt.SQL.String(), "Expected the first query not to change the routed db")
+}
@@ -0,0 +1,33 @@
+package gormrepository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	timeoutContextKey = "__timeout"
+)
+
+// WithTimeout returns an option bounding a single call to d. The context of the call is
+// cancelled once d elapses, which cancels the running query at the driver level, so an
+// endpoint can use tighter bounds than the deadline of its request context.
+func WithTimeout(d time.Duration) Option {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(timeoutContextKey, d)
+	}
+}
+
+// withTimeout binds db to ctx, bounded by the timeout set with WithTimeout if any.
+// cancel releases the timeout and must be called once the call is done.
+func withTimeout(ctx context.Context, db *gorm.DB) (*gorm.DB, context.CancelFunc) {
+	if value, ok := db.Get(timeoutContextKey); ok {
+		if d, ok := value.(time.Duration); ok && d > 0 {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			return db.WithContext(ctx), cancel
+		}
+	}
+	return db.WithContext(ctx), func() {}
+}
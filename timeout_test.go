@@ -0,0 +1,34 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_WithTimeout(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Id: uuid.New(), Value: "bounded"}
+	require.NoError(t, repo.Create(ctx, entity, WithTimeout(time.Minute)), "Create within the timeout should not fail")
+
+	found, err := repo.FindById(ctx, entity.Id, WithTimeout(time.Minute))
+	require.NoError(t, err, "FindById within the timeout should not fail")
+	require.Equal(t, entity.Value, found.Value)
+
+	_, err = repo.FindById(ctx, entity.Id, WithTimeout(time.Nanosecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded, "Expected the query to be cancelled")
+
+	err = repo.UpdateById(ctx, entity.Id, &tests.TestSimpleEntity{Value: "late"}, WithTimeout(time.Nanosecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded, "Expected the update to be cancelled")
+
+	found, err = repo.FindById(ctx, entity.Id)
+	require.NoError(t, err, "FindById should not fail")
+	require.Equal(t, "bounded", found.Value, "Expected the cancelled update not to be applied")
+}
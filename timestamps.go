@@ -0,0 +1,84 @@
+package gormrepository
+
+import (
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Timestamp fields maintained by WithManagedTimestamps
+const (
+	createdAtField = "CreatedAt"
+	updatedAtField = "UpdatedAt"
+)
+
+// WithManagedTimestamps makes the repository maintain the CreatedAt and UpdatedAt fields
+// (time.Time or *time.Time) of its entities, whatever the column names are, e.g. the
+// createdAt/updatedAt columns of utils.CamelCaseNamingStrategy. Create and Save set CreatedAt
+// when it is zero, and UpdatedAt is bumped by every write that changes the entity, including
+// BulkUpdate and the map based updates.
+func WithManagedTimestamps() RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.managedTimestamps = true
+	}
+}
+
+// touch sets CreatedAt when it is zero and UpdatedAt to now, for writes of the whole entity
+func (r *GormRepository[T]) touch(entity *T) {
+	if !r.config.managedTimestamps {
+		return
+	}
+
+	now := time.Now()
+	setTimeField(entity, createdAtField, now, true)
+	setTimeField(entity, updatedAtField, now, false)
+}
+
+// touchUpdate returns the update values with UpdatedAt set to now, and sets it on entity
+// when not nil. values itself is never modified, and values already updating UpdatedAt are kept.
+func (r *GormRepository[T]) touchUpdate(db *gorm.DB, entity *T, values map[string]interface{}) map[string]interface{} {
+	if !r.config.managedTimestamps {
+		return values
+	}
+
+	now := time.Now()
+	touched := withFieldValue[T](db, values, updatedAtField, now)
+	if len(touched) > len(values) && entity != nil {
+		setTimeField(entity, updatedAtField, now, false)
+	}
+
+	return touched
+}
+
+// setTimeField sets the time.Time or *time.Time field name of entity to t.
+// With onlyIfZero, fields that are already set are kept.
+func setTimeField(entity interface{}, name string, t time.Time, onlyIfZero bool) {
+	value := reflect.ValueOf(entity)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	field := value.FieldByName(name)
+	if !field.IsValid() || !field.CanSet() {
+		return
+	}
+
+	timeType := reflect.TypeOf(t)
+	switch {
+	case field.Type() == timeType:
+		if !onlyIfZero || field.Interface().(time.Time).IsZero() {
+			field.Set(reflect.ValueOf(t))
+		}
+	case field.Kind() == reflect.Ptr && field.Type().Elem() == timeType:
+		if !onlyIfZero || field.IsNil() || field.Elem().Interface().(time.Time).IsZero() {
+			field.Set(reflect.ValueOf(&t))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type testTimestampedEntity struct {
+	Id        uuid.UUID  `gorm:"type:text;primary_key" json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	UpdatedAt *time.Time `gorm:"column:updatedAt" json:"updatedAt"`
+}
+
+func (new *testTimestampedEntity) Diff(old *testTimestampedEntity) map[string]interface{} {
+	diff := make(map[string]interface{})
+	if new.Name != old.Name {
+		diff["name"] = new.Name
+	}
+	return diff
+}
+
+func (original *testTimestampedEntity) Clone() *testTimestampedEntity {
+	clone := *original
+	return &clone
+}
+
+func TestGormRepository_ManagedTimestamps(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&testTimestampedEntity{}), "Failed to migrate timestamped entity")
+
+	repo := NewGormRepository[testTimestampedEntity](db, WithManagedTimestamps())
+	ctx := context.Background()
+
+	entity := &testTimestampedEntity{Id: uuid.New(), Name: "created"}
+	require.NoError(t, repo.Create(ctx, entity), "Create should not fail")
+	require.False(t, entity.CreatedAt.IsZero(), "Expected CreatedAt to be set")
+	require.NotNil(t, entity.UpdatedAt, "Expected UpdatedAt to be set")
+	createdAt, firstUpdate := entity.CreatedAt, *entity.UpdatedAt
+
+	time.Sleep(time.Millisecond)
+	err := repo.UpdateInPlace(ctx, entity, func() {
+		entity.Name = "updated"
+	})
+	require.NoError(t, err, "UpdateInPlace should not fail")
+	require.True(t, entity.UpdatedAt.After(firstUpdate), "Expected UpdatedAt to be bumped")
+	require.True(t, entity.CreatedAt.Equal(createdAt), "Expected CreatedAt to be kept")
+	secondUpdate := *entity.UpdatedAt
+
+	err = repo.UpdateInPlace(ctx, entity, func() {})
+	require.NoError(t, err, "UpdateInPlace should not fail")
+	require.True(t, entity.UpdatedAt.Equal(secondUpdate), "Expected no bump without changes")
+
+	time.Sleep(time.Millisecond)
+	_, err = repo.UpdateByIdWithMap(ctx, entity.Id, map[string]interface{}{"name": "from map"})
+	require.NoError(t, err, "UpdateByIdWithMap should not fail")
+
+	found, err := repo.FindById(ctx, entity.Id)
+	require.NoError(t, err, "FindById should not fail")
+	require.Equal(t, "from map", found.Name)
+	require.True(t, found.UpdatedAt.After(secondUpdate), "Expected map updates to bump UpdatedAt")
+	require.True(t, found.CreatedAt.Equal(createdAt), "Expected CreatedAt to be kept")
+}
@@ -0,0 +1,34 @@
+package gormrepository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key of the transaction set with ContextWithTx
+type txKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying tx, for WithTxFromContext
+func ContextWithTx(ctx context.Context, tx *Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction placed in ctx with ContextWithTx
+func TxFromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*Tx)
+	return tx, ok && tx != nil
+}
+
+// WithTxFromContext returns an option running the query within the transaction placed in ctx
+// with ContextWithTx, like WithTx. Without one the query runs outside of a transaction, so
+// call sites can adopt it before every caller provides a transaction.
+func WithTxFromContext(ctx context.Context) Option {
+	tx, ok := TxFromContext(ctx)
+	if !ok {
+		return func(db *gorm.DB) *gorm.DB {
+			return db
+		}
+	}
+	return WithTx(tx)
+}
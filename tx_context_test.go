@@ -0,0 +1,34 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_WithTxFromContext(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+
+	tx := repo.BeginTransaction()
+	ctx := ContextWithTx(context.Background(), tx)
+
+	found, ok := TxFromContext(ctx)
+	require.True(t, ok)
+	require.Same(t, tx, found)
+
+	require.NoError(t, repo.Create(ctx, &tests.TestSimpleEntity{Value: "in tx"}, WithTxFromContext(ctx)), "Create should not fail")
+	require.NoError(t, tx.Rollback(), "Rollback should not fail")
+
+	entities, err := repo.FindMany(context.Background())
+	require.NoError(t, err, "FindMany should not fail")
+	require.Empty(t, entities, "Expected the write to run in the rolled back transaction")
+
+	plain := context.Background()
+	require.NoError(t, repo.Create(plain, &tests.TestSimpleEntity{Value: "no tx"}, WithTxFromContext(plain)), "Create without transaction should not fail")
+	entities, err = repo.FindMany(plain)
+	require.NoError(t, err, "FindMany should not fail")
+	require.Len(t, entities, 1)
+}
@@ -0,0 +1,78 @@
+package gormrepository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TenantSetting is the Postgres configuration parameter conventionally read by
+// row-level security policies, e.g. USING (tenant_id = current_setting('app.tenant_id')::uuid)
+const TenantSetting = "app.tenant_id"
+
+// TxBeginHook runs at the start of every transaction created by BeginTransactionWithContext
+// and RunInTransaction, before any statement of the caller. tx is bound to the new transaction.
+// Returning an error rolls the transaction back.
+type TxBeginHook func(ctx context.Context, tx *gorm.DB) error
+
+// TxEndHook runs once a transaction created by BeginTransactionWithContext or RunInTransaction
+// commits or rolls back, with the time elapsed since it began
+type TxEndHook func(ctx context.Context, committed bool, duration time.Duration)
+
+// WithTxBeginHooks adds hooks run at the start of the transactions of the repository, in order
+func WithTxBeginHooks(hooks ...TxBeginHook) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.txBeginHooks = append(config.txBeginHooks, hooks...)
+	}
+}
+
+// WithTxEndHooks adds hooks run when the transactions of the repository end, in order
+func WithTxEndHooks(hooks ...TxEndHook) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.txEndHooks = append(config.txEndHooks, hooks...)
+	}
+}
+
+// WithRowLevelSecurity sets TenantSetting to the tenant returned by tenant at the start of
+// every repository transaction, so Postgres RLS policies apply to the statements run within it.
+// Writes outside of a transaction are not covered.
+func WithRowLevelSecurity(tenant func(ctx context.Context) string) RepositoryOption {
+	return WithTxBeginHooks(SetLocal(TenantSetting, tenant))
+}
+
+// SetLocal returns a TxBeginHook that sets the Postgres configuration parameter name for the
+// duration of the transaction, like SET LOCAL. set_config is used since SET doesn't accept bind
+// parameters. Nothing is set when value returns an empty string.
+func SetLocal(name string, value func(ctx context.Context) string) TxBeginHook {
+	return func(ctx context.Context, tx *gorm.DB) error {
+		setting := value(ctx)
+		if setting == "" {
+			return nil
+		}
+		return tx.Exec("SELECT set_config(?, ?, true)", name, setting).Error
+	}
+}
+
+// runTxBeginHooks runs the transaction hooks of the repository, stopping at the first error
+func (r *GormRepository[T]) runTxBeginHooks(ctx context.Context, gtx *gorm.DB) error {
+	for _, hook := range r.config.txBeginHooks {
+		if err := hook(ctx, gtx.Session(&gorm.Session{NewDB: true})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runEndHooks runs the TxEndHooks of the transaction, once
+func (tx *Tx) runEndHooks(committed bool) {
+	tx.mutex.Lock()
+	hooks := tx.endHooks
+	tx.endHooks = nil
+	tx.mutex.Unlock()
+
+	duration := time.Since(tx.startedAt)
+	for _, hook := range hooks {
+		hook(tx.ctx, committed, duration)
+	}
+}
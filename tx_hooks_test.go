@@ -0,0 +1,85 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type tenantContextKey struct{}
+
+func TestGormRepository_RunInTransaction_RunsTxBeginHooks(t *testing.T) {
+	db := setupTestDB(t)
+	var tenants []string
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithTxBeginHooks(func(ctx context.Context, tx *gorm.DB) error {
+		tenant, _ := ctx.Value(tenantContextKey{}).(string)
+		tenants = append(tenants, tenant)
+		// Written within the transaction, so it is rolled back with it
+		return tx.Create(&tests.TestSimpleEntity{Id: uuid.New(), Value: "hook"}).Error
+	}))
+	ctx := context.WithValue(context.Background(), tenantContextKey{}, "tenant-a")
+
+	err := repo.RunInTransaction(ctx, func(tx *Tx) error {
+		return repo.Create(ctx, &tests.TestSimpleEntity{Value: "committed"}, WithTx(tx))
+	})
+	require.NoError(t, err, "RunInTransaction should not fail")
+
+	failure := errors.New("abort")
+	err = repo.RunInTransaction(ctx, func(tx *Tx) error {
+		require.NoError(t, repo.Create(ctx, &tests.TestSimpleEntity{Value: "rolled back"}, WithTx(tx)))
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	require.Equal(t, []string{"tenant-a", "tenant-a"}, tenants, "Expected the hook to run once per transaction")
+
+	var values []string
+	require.NoError(t, db.Model(&tests.TestSimpleEntity{}).Order("value").Pluck("value", &values).Error)
+	require.Equal(t, []string{"committed", "hook"}, values, "Expected only the committed transaction to persist")
+}
+
+func TestGormRepository_BeginTransactionWithContext_HookError(t *testing.T) {
+	db := setupTestDB(t)
+	failure := errors.New("no tenant")
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithTxBeginHooks(func(ctx context.Context, tx *gorm.DB) error {
+		return failure
+	}))
+	ctx := context.Background()
+
+	tx := repo.BeginTransactionWithContext(ctx)
+	require.ErrorIs(t, tx.Error(), failure, "Expected the hook error on the transaction")
+	require.Error(t, repo.Create(ctx, &tests.TestSimpleEntity{Value: "rejected"}, WithTx(tx)), "Expected writes in the failed transaction to fail")
+
+	called := false
+	err := repo.RunInTransaction(ctx, func(tx *Tx) error {
+		called = true
+		return nil
+	})
+	require.ErrorIs(t, err, failure)
+	require.False(t, called, "Expected fn not to run when a hook fails")
+}
+
+func TestGormRepository_TxEndHooks(t *testing.T) {
+	db := setupTestDB(t)
+	var outcomes []bool
+	repo := NewGormRepository[tests.TestSimpleEntity](db, WithTxEndHooks(func(ctx context.Context, committed bool, duration time.Duration) {
+		require.Positive(t, duration)
+		outcomes = append(outcomes, committed)
+	}))
+	ctx := context.Background()
+
+	require.NoError(t, repo.RunInTransaction(ctx, func(tx *Tx) error { return nil }))
+	require.Error(t, repo.RunInTransaction(ctx, func(tx *Tx) error { return errors.New("abort") }))
+
+	tx := repo.BeginTransactionWithContext(ctx)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, tx.Rollback(), "Rollback after commit should be a no-op")
+
+	require.Equal(t, []bool{true, false, true}, outcomes, "Expected the hooks to run once per transaction")
+}
@@ -0,0 +1,82 @@
+package gormrepository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTx_BeginTransaction_Savepoints(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	tx := repo.BeginTransaction()
+	require.NoError(t, repo.Create(ctx, &tests.TestSimpleEntity{Value: "outer"}, WithTx(tx)))
+
+	rolledBack := tx.BeginTransaction()
+	require.NoError(t, rolledBack.Error(), "Nested transaction should begin")
+	require.NoError(t, repo.Create(ctx, &tests.TestSimpleEntity{Value: "rolled back"}, WithTx(rolledBack)))
+	require.NoError(t, rolledBack.Rollback(), "Nested rollback should not fail")
+
+	released := tx.BeginTransaction()
+	require.NoError(t, repo.Create(ctx, &tests.TestSimpleEntity{Value: "released"}, WithTx(released)))
+	callbacks := 0
+	released.OnCommit(func() { callbacks++ })
+	require.NoError(t, released.Commit(), "Nested commit should not fail")
+	require.Zero(t, callbacks, "Expected nested OnCommit callbacks to wait for the outer commit")
+
+	entities, err := repo.FindMany(ctx, WithTx(tx))
+	require.NoError(t, err, "FindMany in transaction should not fail")
+	require.Len(t, entities, 2, "Expected only the nested rollback to be undone")
+
+	require.NoError(t, tx.Commit(), "Commit should not fail")
+	require.Equal(t, 1, callbacks)
+
+	var values []string
+	require.NoError(t, db.Model(&tests.TestSimpleEntity{}).Order("value").Pluck("value", &values).Error)
+	require.Equal(t, []string{"outer", "released"}, values)
+}
+
+func TestTx_BeginTransaction_PropagatesSnapshots(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Value: "original"}
+	require.NoError(t, repo.Create(ctx, entity))
+
+	tx := repo.BeginTransaction()
+	defer tx.Rollback()
+	found, err := repo.FindById(ctx, entity.Id, WithTx(tx))
+	require.NoError(t, err)
+
+	nested := tx.BeginTransaction()
+	_, isSnapshot := getCloneForDiff(WithTx(nested)(db), found)
+	require.True(t, isSnapshot, "Expected the nested transaction to see the snapshots of its parent")
+
+	require.NoError(t, nested.Commit())
+}
+
+func TestGormRepository_RunInTransactionWithOptions(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	err := repo.RunInTransactionWithOptions(ctx, sql.TxOptions{Isolation: sql.LevelSerializable}, func(tx *Tx) error {
+		if db.Dialector.Name() == "postgres" {
+			var isolation string
+			require.NoError(t, tx.gtx.Raw("SELECT current_setting('transaction_isolation')").Scan(&isolation).Error)
+			require.Equal(t, "serializable", isolation)
+		}
+		return repo.Create(ctx, &tests.TestSimpleEntity{Value: "serializable"}, WithTx(tx))
+	})
+	require.NoError(t, err, "RunInTransactionWithOptions should not fail")
+
+	entities, err := repo.FindMany(ctx)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+}
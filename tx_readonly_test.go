@@ -0,0 +1,81 @@
+package gormrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormRepository_RunInReadOnlyTransaction(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Value: "original"}
+	require.NoError(t, repo.Create(ctx, entity))
+
+	err := repo.RunInReadOnlyTransaction(ctx, func(tx *Tx) error {
+		require.True(t, tx.ReadOnly())
+
+		found, err := repo.FindById(ctx, entity.Id, WithTx(tx))
+		require.NoError(t, err, "Reads should run in read-only transactions")
+		require.Equal(t, "original", found.Value)
+
+		found.Value = "changed"
+		err = repo.Save(ctx, found, WithTx(tx))
+		require.ErrorIs(t, err, ErrReadOnlyTransaction)
+
+		nested := tx.BeginTransaction()
+		require.True(t, nested.ReadOnly(), "Expected nested transactions to stay read-only")
+		err = repo.Create(ctx, &tests.TestSimpleEntity{Value: "nested"}, WithTx(nested))
+		require.ErrorIs(t, err, ErrReadOnlyTransaction)
+		return nested.Rollback()
+	})
+	require.NoError(t, err)
+
+	var values []string
+	require.NoError(t, db.Model(&tests.TestSimpleEntity{}).Pluck("value", &values).Error)
+	require.Equal(t, []string{"original"}, values)
+
+	tx := repo.BeginTransaction()
+	defer tx.Rollback()
+	require.False(t, tx.ReadOnly())
+	require.NoError(t, repo.Create(ctx, &tests.TestSimpleEntity{Value: "written"}, WithTx(tx)))
+}
+
+func TestGormRepository_RunInReadOnlyTransaction_SkipsHooks(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestSimpleEntity](db)
+	ctx := context.Background()
+
+	entity := &tests.TestSimpleEntity{Value: "original"}
+	require.NoError(t, repo.Create(ctx, entity))
+
+	calls := 0
+	countCall := func(ctx context.Context, entity *tests.TestSimpleEntity) error {
+		calls++
+		return nil
+	}
+	repo.RegisterHook(HookBeforeCreate, countCall)
+	repo.RegisterHook(HookBeforeUpdate, countCall)
+	repo.RegisterHook(HookBeforeDelete, countCall)
+
+	err := repo.RunInReadOnlyTransaction(ctx, func(tx *Tx) error {
+		err := repo.Create(ctx, &tests.TestSimpleEntity{Value: "created"}, WithTx(tx))
+		require.ErrorIs(t, err, ErrReadOnlyTransaction)
+
+		err = repo.Save(ctx, &tests.TestSimpleEntity{Id: entity.Id, Value: "saved"}, WithTx(tx))
+		require.ErrorIs(t, err, ErrReadOnlyTransaction)
+
+		_, err = repo.UpdateByIdWithMap(ctx, entity.Id, map[string]interface{}{"value": "mapped"}, WithTx(tx))
+		require.ErrorIs(t, err, ErrReadOnlyTransaction)
+
+		err = repo.DeleteById(ctx, entity.Id, WithTx(tx))
+		require.ErrorIs(t, err, ErrReadOnlyTransaction)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Zero(t, calls, "Expected writes in read-only transactions to be rejected before hooks run")
+}
@@ -0,0 +1,247 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ApplyDiff mutates entity according to a diff produced by a Diff method, the inverse of Diff.
+// Keys are matched against JSON names first and struct field names second, and flattened
+// JSONB paths ("whatsAppData.status.mode", "items.3", "items.-") are followed through nested
+// structs, pointers, maps and slices. Removed deletes map keys (or zeroes other values) and
+// "? || ?" merge expressions replace the top level keys they carry, like they do in the database.
+// This allows replaying recorded diffs and reconciling cached copies without a database read.
+func ApplyDiff[T any](entity *T, diff map[string]interface{}) error {
+	if entity == nil {
+		return errors.New("entity must not be nil")
+	}
+
+	flat, err := FlattenDiff(diff)
+	if err != nil {
+		return err
+	}
+
+	// Apply shorter paths first so "data" is set before "data.day"
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := reflect.ValueOf(entity).Elem()
+	for _, key := range keys {
+		if err := applyDiffPath(root, strings.Split(key, "."), flat[key]); err != nil {
+			return fmt.Errorf("apply %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// applyDiffPath walks the path segments from target and assigns value at the end
+func applyDiffPath(target reflect.Value, segments []string, value interface{}) error {
+	segment := segments[0]
+	last := len(segments) == 1
+
+	// Follow pointers, allocating the ones that are still nil
+	for target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	switch target.Kind() {
+	case reflect.Struct:
+		field, err := structFieldByDiffKey(target, segment)
+		if err != nil {
+			return err
+		}
+		if last {
+			return assignDiffValue(field, value)
+		}
+		return applyDiffPath(field, segments[1:], value)
+
+	case reflect.Map:
+		if target.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", target.Type().Key())
+		}
+		key := reflect.ValueOf(segment).Convert(target.Type().Key())
+
+		if last && value == Removed {
+			if !target.IsNil() {
+				target.SetMapIndex(key, reflect.Value{})
+			}
+			return nil
+		}
+
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+
+		// Map elements aren't addressable, so work on a copy and store it back
+		element := reflect.New(target.Type().Elem()).Elem()
+		if existing := target.MapIndex(key); existing.IsValid() {
+			element.Set(existing)
+		}
+
+		var err error
+		if last {
+			err = assignDiffValue(element, value)
+		} else {
+			err = applyDiffPath(element, segments[1:], value)
+		}
+		if err != nil {
+			return err
+		}
+
+		target.SetMapIndex(key, element)
+		return nil
+
+	case reflect.Slice:
+		if segment == SliceAppendSegment {
+			if !last {
+				return errors.New("append segment must be the last path segment")
+			}
+			return appendDiffValues(target, value)
+		}
+
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= target.Len() {
+			return fmt.Errorf("invalid slice index %q for length %d", segment, target.Len())
+		}
+		if last {
+			return assignDiffValue(target.Index(index), value)
+		}
+		return applyDiffPath(target.Index(index), segments[1:], value)
+
+	case reflect.Interface:
+		if target.IsNil() {
+			if last {
+				return assignDiffValue(target, value)
+			}
+			target.Set(reflect.ValueOf(map[string]interface{}{}))
+		}
+
+		// Values held by an interface aren't settable, so work on a copy and store it back
+		inner := reflect.New(target.Elem().Type()).Elem()
+		inner.Set(target.Elem())
+		if err := applyDiffPath(inner, segments, value); err != nil {
+			return err
+		}
+		target.Set(inner)
+		return nil
+
+	default:
+		return fmt.Errorf("can't follow path segment %q into %s", segment, target.Type())
+	}
+}
+
+// appendDiffValues appends the elements stored under an "items.-" path to target
+func appendDiffValues(target reflect.Value, value interface{}) error {
+	elements := reflect.ValueOf(value)
+	if elements.Kind() != reflect.Slice && elements.Kind() != reflect.Array {
+		return fmt.Errorf("appended elements must be a slice, got %T", value)
+	}
+
+	for i := 0; i < elements.Len(); i++ {
+		element := reflect.New(target.Type().Elem()).Elem()
+		if err := assignDiffValue(element, elements.Index(i).Interface()); err != nil {
+			return err
+		}
+		target.Set(reflect.Append(target, element))
+	}
+
+	return nil
+}
+
+// assignDiffValue stores value into target, converting it through JSON when the types differ
+func assignDiffValue(target reflect.Value, value interface{}) error {
+	if value == nil || value == Removed {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	source := reflect.ValueOf(value)
+	if source.Type().AssignableTo(target.Type()) {
+		target.Set(source)
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr && source.Type().AssignableTo(target.Type().Elem()) {
+		pointer := reflect.New(target.Type().Elem())
+		pointer.Elem().Set(source)
+		target.Set(pointer)
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	converted := reflect.New(target.Type())
+	if err := json.Unmarshal(data, converted.Interface()); err != nil {
+		return err
+	}
+
+	target.Set(converted.Elem())
+	return nil
+}
+
+// structFieldByDiffKey finds the field matching a diff key by JSON name, field name,
+// or case-insensitive field name, in that order
+func structFieldByDiffKey(target reflect.Value, key string) (reflect.Value, error) {
+	index, ok := structFieldIndexByDiffKey(target.Type(), key)
+	if !ok {
+		return reflect.Value{}, errors.New("field not found in entity: " + key)
+	}
+	return fieldByIndexAlloc(target, index), nil
+}
+
+// structFieldIndexByDiffKey returns the index of the field of structType matching a diff key,
+// matched as structFieldByDiffKey does
+func structFieldIndexByDiffKey(structType reflect.Type, key string) ([]int, bool) {
+	fields := reflect.VisibleFields(structType)
+
+	matchers := []func(field reflect.StructField) bool{
+		func(field reflect.StructField) bool {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			return name == key
+		},
+		func(field reflect.StructField) bool { return field.Name == key },
+		func(field reflect.StructField) bool { return strings.EqualFold(field.Name, key) },
+	}
+
+	for _, matches := range matchers {
+		for _, field := range fields {
+			if field.Anonymous || !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			if matches(field) {
+				return field.Index, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// fieldByIndexAlloc is reflect.Value.FieldByIndex allocating nil embedded struct pointers on the way
+func fieldByIndexAlloc(target reflect.Value, index []int) reflect.Value {
+	for i, position := range index {
+		if i > 0 && target.Kind() == reflect.Ptr {
+			if target.IsNil() {
+				target.Set(reflect.New(target.Type().Elem()))
+			}
+			target = target.Elem()
+		}
+		target = target.Field(position)
+	}
+	return target
+}
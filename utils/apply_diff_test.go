@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type applyStatus struct {
+	Mode  string `json:"mode,omitempty"`
+	State string `json:"state,omitempty"`
+}
+
+type applyData struct {
+	Error  string         `json:"error,omitempty"`
+	Status *applyStatus   `json:"status,omitempty"`
+	Items  []string       `json:"items,omitempty"`
+	Labels map[string]int `json:"labels,omitempty"`
+}
+
+type applyEntity struct {
+	Id       uuid.UUID  `json:"id"`
+	Name     string     `json:"name"`
+	Age      int        `json:"age"`
+	Data     *applyData `json:"data,omitempty"`
+	LogLevel int
+}
+
+func TestApplyDiff_PlainFields(t *testing.T) {
+	id := uuid.New()
+	entity := &applyEntity{Name: "John", Age: 30}
+
+	err := ApplyDiff(entity, map[string]interface{}{
+		"id":       id.String(),
+		"name":     "Jane",
+		"age":      31,
+		"LogLevel": 2,
+	})
+	if err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+
+	expected := &applyEntity{Id: id, Name: "Jane", Age: 31, LogLevel: 2}
+	if !reflect.DeepEqual(entity, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entity)
+	}
+}
+
+func TestApplyDiff_NestedPaths(t *testing.T) {
+	entity := &applyEntity{
+		Data: &applyData{
+			Status: &applyStatus{Mode: "QR", State: "NORMAL"},
+			Items:  []string{"a", "b"},
+			Labels: map[string]int{"old": 1, "keep": 2},
+		},
+	}
+
+	err := ApplyDiff(entity, map[string]interface{}{
+		"data.status.mode": "CONNECTED",
+		"data.items.1":     "B",
+		"data.items.-":     []string{"c"},
+		"data.labels.old":  Removed,
+		"data.labels.new":  3,
+	})
+	if err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+
+	expected := &applyData{
+		Status: &applyStatus{Mode: "CONNECTED", State: "NORMAL"},
+		Items:  []string{"a", "B", "c"},
+		Labels: map[string]int{"keep": 2, "new": 3},
+	}
+	if !reflect.DeepEqual(entity.Data, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entity.Data)
+	}
+}
+
+func TestApplyDiff_MergeExpression(t *testing.T) {
+	entity := &applyEntity{
+		Data: &applyData{Error: "none", Status: &applyStatus{Mode: "QR"}},
+	}
+
+	err := ApplyDiff(entity, map[string]interface{}{
+		"data": gorm.Expr("? || ?", clause.Column{Name: "data"}, `{"status":{"state":"NORMAL"}}`),
+	})
+	if err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+
+	if entity.Data.Error != "none" {
+		t.Errorf("Expected untouched keys to be preserved, got %+v", entity.Data)
+	}
+	// || replaces top level keys, so status is replaced as a whole
+	if !reflect.DeepEqual(entity.Data.Status, &applyStatus{State: "NORMAL"}) {
+		t.Errorf("Expected status to be replaced, got %+v", entity.Data.Status)
+	}
+}
+
+func TestApplyDiff_NilAndAllocation(t *testing.T) {
+	entity := &applyEntity{Data: &applyData{Error: "x"}}
+
+	if err := ApplyDiff(entity, map[string]interface{}{"data": nil}); err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+	if entity.Data != nil {
+		t.Errorf("Expected data to be nil, got %+v", entity.Data)
+	}
+
+	if err := ApplyDiff(entity, map[string]interface{}{"data.status.mode": "QR"}); err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+	if entity.Data == nil || entity.Data.Status == nil || entity.Data.Status.Mode != "QR" {
+		t.Errorf("Expected nil pointers to be allocated along the path, got %+v", entity.Data)
+	}
+}
+
+func TestApplyDiff_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		diff map[string]interface{}
+	}{
+		{name: "unknown field", diff: map[string]interface{}{"unknown": 1}},
+		{name: "index out of range", diff: map[string]interface{}{"data.items.5": "x"}},
+		{name: "path into scalar", diff: map[string]interface{}{"name.first": "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entity := &applyEntity{Data: &applyData{Items: []string{"a"}}}
+			if err := ApplyDiff(entity, tt.diff); err == nil {
+				t.Error("Expected error, but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SliceAppendSegment is the last segment of a flattened diff path holding the elements
+// appended to the end of a slice, e.g. "items.-" (same token as JSON Pointer's end of array).
+// The value stored under such a path is always a slice with the new elements.
+const SliceAppendSegment = "-"
+
+// removedValue is the type of Removed
+type removedValue struct{}
+
+// Removed is stored under a flattened diff path whose key was deleted (e.g. a map entry).
+// The repository removes such paths from the JSONB document with the #- operator.
+var Removed interface{} = removedValue{}
+
+// DiffSlice compares two slices element by element (by index) and records the changes in diff
+// using flattened paths understood by the repository:
+//   - "field.<index>" for every element that changed
+//   - "field.-" with the elements appended to the end of the slice
+//
+// The whole slice is stored under field when old is empty or when new is shorter than old,
+// because removing elements can't be expressed element by element.
+// Usage inside a Diff method: utils.DiffSlice(diff, "items", new.Items, old.Items)
+func DiffSlice[E any](diff map[string]interface{}, field string, new, old []E) {
+	if reflect.DeepEqual(new, old) {
+		return
+	}
+
+	if new == nil || len(old) == 0 || len(new) < len(old) {
+		diff[field] = new
+		return
+	}
+
+	for i := range old {
+		if !reflect.DeepEqual(new[i], old[i]) {
+			diff[field+"."+strconv.Itoa(i)] = new[i]
+		}
+	}
+
+	if len(new) > len(old) {
+		diff[field+"."+SliceAppendSegment] = new[len(old):]
+	}
+}
+
+// DiffSliceByKey works like DiffSlice but matches elements by the key returned by key (usually the
+// element id). Elements are only diffed in place when the keys of old are a prefix of the keys of new,
+// that is, when elements were only modified or appended. Any insertion, removal or reordering
+// stores the whole slice under field instead of producing a cascade of shifted element updates.
+func DiffSliceByKey[E any, K comparable](diff map[string]interface{}, field string, new, old []E, key func(E) K) {
+	if reflect.DeepEqual(new, old) {
+		return
+	}
+
+	if new == nil || len(old) == 0 || len(new) < len(old) {
+		diff[field] = new
+		return
+	}
+
+	for i := range old {
+		if key(new[i]) != key(old[i]) {
+			diff[field] = new
+			return
+		}
+	}
+
+	DiffSlice(diff, field, new, old)
+}
+
+// DiffMap compares two maps key by key and records the changes in diff using flattened
+// paths (field.key), so only the touched keys are written with jsonb_set:
+//   - "field.<key>" with the new value for every added or changed key
+//   - "field.<key>" set to Removed for every deleted key
+//
+// The whole map is stored under field when old or new is empty, or when a key contains
+// a dot (it would be read back as a nested path).
+// Usage inside a Diff method: utils.DiffMap(diff, "labels", new.Labels, old.Labels)
+func DiffMap[V any](diff map[string]interface{}, field string, new, old map[string]V) {
+	if reflect.DeepEqual(new, old) {
+		return
+	}
+
+	if len(new) == 0 || len(old) == 0 || !isFlattenableMap(new) || !isFlattenableMap(old) {
+		diff[field] = new
+		return
+	}
+
+	for key, value := range new {
+		if oldValue, exists := old[key]; !exists || !reflect.DeepEqual(value, oldValue) {
+			diff[field+"."+key] = value
+		}
+	}
+
+	for key := range old {
+		if _, exists := new[key]; !exists {
+			diff[field+"."+key] = Removed
+		}
+	}
+}
+
+// isFlattenableMap reports whether every key can be used as a flattened path segment
+func isFlattenableMap[V any](m map[string]V) bool {
+	for key := range m {
+		if key == "" || key == SliceAppendSegment || strings.Contains(key, ".") {
+			return false
+		}
+	}
+	return true
+}
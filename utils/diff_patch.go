@@ -0,0 +1,299 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// PatchOperation is a single JSON Patch (RFC 6902) operation
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON keeps the value member for add/replace operations even when it is
+// null, false or zero, and drops it for remove operations
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+
+	return json.Marshal(struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}{o.Op, o.Path, o.Value})
+}
+
+// FlattenDiff expands the "? || ?" merge expressions generated for JSONB fields into
+// flattened paths (field.key), so the returned diff only holds plain values.
+// Other SQL expressions can't be rendered outside the database and return an error.
+func FlattenDiff(diff map[string]interface{}) (map[string]interface{}, error) {
+	flat := make(map[string]interface{}, len(diff))
+
+	for key, value := range diff {
+		expr, ok := value.(clause.Expr)
+		if !ok {
+			flat[key] = value
+			continue
+		}
+
+		merged, err := decodeMergeExpr(expr)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", key, err)
+		}
+
+		for subKey, subValue := range merged {
+			flat[key+"."+subKey] = subValue
+		}
+	}
+
+	return flat, nil
+}
+
+// decodeMergeExpr extracts the JSON object merged by a "? || ?" expression
+func decodeMergeExpr(expr clause.Expr) (map[string]interface{}, error) {
+	if strings.TrimSpace(expr.SQL) != "? || ?" || len(expr.Vars) != 2 {
+		return nil, errors.New("unsupported SQL expression in diff: " + expr.SQL)
+	}
+
+	var data []byte
+	switch value := expr.Vars[1].(type) {
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	default:
+		return nil, fmt.Errorf("unsupported merge value of type %T", value)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var merged map[string]interface{}
+	if err := decoder.Decode(&merged); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// GroupDiff splits a diff into plain fields and flattened paths grouped by their root field.
+// For "whatsAppData.status.mode" the root is "whatsAppData" and the sub path "status.mode".
+// This is the grouping used to build jsonb_set expressions and merge patch documents.
+func GroupDiff(diff map[string]interface{}) (fields map[string]interface{}, nested map[string]map[string]interface{}) {
+	fields = make(map[string]interface{})
+	nested = make(map[string]map[string]interface{})
+
+	for key, value := range diff {
+		root, subPath, found := strings.Cut(key, ".")
+		if !found {
+			fields[key] = value
+			continue
+		}
+
+		if nested[root] == nil {
+			nested[root] = make(map[string]interface{})
+		}
+		nested[root][subPath] = value
+	}
+
+	return fields, nested
+}
+
+// JSONPatch renders a diff as a JSON Patch (RFC 6902) document, so the same change
+// detection used for SQL updates can drive HTTP PATCH APIs and event payloads.
+// Flattened paths become JSON Pointers ("whatsAppData.status.mode" -> "/whatsAppData/status/mode"):
+//   - Removed values become "remove" operations
+//   - elements appended to a slice ("items.-") become one "add" to "/items/-" per element
+//   - changed slice elements ("items.3") become "replace" operations
+//   - every other path becomes an "add" operation, which also replaces existing members
+//
+// old is the value the diff was computed against. It tells slice elements from map members
+// with numeric keys ("labels.7"), which are added when they are new, as "replace" needs an
+// existing target; with a nil old, numeric last segments are taken as slice elements.
+// Operations are sorted by path so the output is deterministic.
+func JSONPatch(diff map[string]interface{}, old interface{}) ([]PatchOperation, error) {
+	flat, err := FlattenDiff(diff)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	operations := make([]PatchOperation, 0, len(keys))
+	for _, key := range keys {
+		value := flat[key]
+		segments := strings.Split(key, ".")
+		last := segments[len(segments)-1]
+
+		switch {
+		case value == Removed:
+			operations = append(operations, PatchOperation{Op: "remove", Path: jsonPointer(segments)})
+
+		case last == SliceAppendSegment:
+			elements := reflect.ValueOf(value)
+			if elements.Kind() != reflect.Slice && elements.Kind() != reflect.Array {
+				return nil, fmt.Errorf("field %s: appended elements must be a slice, got %T", key, value)
+			}
+			for i := 0; i < elements.Len(); i++ {
+				operations = append(operations, PatchOperation{Op: "add", Path: jsonPointer(segments), Value: elements.Index(i).Interface()})
+			}
+
+		case len(segments) > 1 && isIndexSegment(last) && isSliceElement(old, segments):
+			operations = append(operations, PatchOperation{Op: "replace", Path: jsonPointer(segments), Value: value})
+
+		default:
+			operations = append(operations, PatchOperation{Op: "add", Path: jsonPointer(segments), Value: value})
+		}
+	}
+
+	return operations, nil
+}
+
+// jsonPointer builds a JSON Pointer (RFC 6901) from path segments
+func jsonPointer(segments []string) string {
+	var builder strings.Builder
+	for _, segment := range segments {
+		builder.WriteByte('/')
+		segment = strings.ReplaceAll(segment, "~", "~0")
+		segment = strings.ReplaceAll(segment, "/", "~1")
+		builder.WriteString(segment)
+	}
+	return builder.String()
+}
+
+// isSliceElement reports whether the path segments lead to a slice element of old, or
+// whether the last segment is an index when old is nil
+func isSliceElement(old interface{}, segments []string) bool {
+	if old == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(old)
+	for i := 0; ; i++ {
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return false
+			}
+			value = value.Elem()
+		}
+		if i == len(segments)-1 {
+			return value.Kind() == reflect.Slice || value.Kind() == reflect.Array
+		}
+
+		segment := segments[i]
+		switch value.Kind() {
+		case reflect.Struct:
+			index, ok := structFieldIndexByDiffKey(value.Type(), segment)
+			if !ok {
+				return false
+			}
+			field, err := value.FieldByIndexErr(index)
+			if err != nil {
+				return false
+			}
+			value = field
+		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return false
+			}
+			value = value.MapIndex(reflect.ValueOf(segment).Convert(value.Type().Key()))
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= value.Len() {
+				return false
+			}
+			value = value.Index(index)
+		default:
+			return false
+		}
+		if !value.IsValid() {
+			return false
+		}
+	}
+}
+
+// isIndexSegment reports whether a path segment is an array index
+func isIndexSegment(segment string) bool {
+	index, err := strconv.Atoi(segment)
+	return err == nil && index >= 0 && strconv.Itoa(index) == segment
+}
+
+// MergePatch renders a diff as a JSON Merge Patch (RFC 7386) document. Flattened paths become
+// nested objects and Removed values become null. Slice element paths ("items.3", "items.-")
+// can't be expressed in a merge patch, which replaces arrays as a whole, and return an error.
+func MergePatch(diff map[string]interface{}) (map[string]interface{}, error) {
+	flat, err := FlattenDiff(diff)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, nested := GroupDiff(flat)
+
+	patch := make(map[string]interface{}, len(fields)+len(nested))
+	for key, value := range fields {
+		if value == Removed {
+			value = nil
+		}
+		patch[key] = value
+	}
+
+	for root, paths := range nested {
+		if _, exists := patch[root]; exists {
+			return nil, fmt.Errorf("field %s is both replaced and patched", root)
+		}
+
+		object := make(map[string]interface{})
+		for subPath, value := range paths {
+			if err := setMergePatchPath(object, strings.Split(subPath, "."), value); err != nil {
+				return nil, fmt.Errorf("field %s.%s: %w", root, subPath, err)
+			}
+		}
+		patch[root] = object
+	}
+
+	return patch, nil
+}
+
+// setMergePatchPath sets value at the path inside a merge patch object, creating intermediate objects
+func setMergePatchPath(object map[string]interface{}, segments []string, value interface{}) error {
+	for i, segment := range segments {
+		if segment == SliceAppendSegment || isIndexSegment(segment) {
+			return errors.New("slice element paths can't be expressed as a merge patch")
+		}
+
+		if i == len(segments)-1 {
+			if value == Removed {
+				value = nil
+			}
+			object[segment] = value
+			return nil
+		}
+
+		child, ok := object[segment].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			object[segment] = child
+		}
+		object = child
+	}
+
+	return nil
+}
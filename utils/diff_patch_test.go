@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestFlattenDiff_ExpandsMergeExpressions(t *testing.T) {
+	diff := map[string]interface{}{
+		"name": "John",
+		"data": gorm.Expr("? || ?", clause.Column{Name: "data"}, `{"day":10,"nickname":"Johnny"}`),
+	}
+
+	flat, err := FlattenDiff(diff)
+	if err != nil {
+		t.Fatalf("FlattenDiff failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":          "John",
+		"data.day":      json.Number("10"),
+		"data.nickname": "Johnny",
+	}
+	if !reflect.DeepEqual(flat, expected) {
+		t.Errorf("Expected %v, got %v", expected, flat)
+	}
+}
+
+func TestFlattenDiff_UnsupportedExpression(t *testing.T) {
+	diff := map[string]interface{}{
+		"age": gorm.Expr("age + ?", 1),
+	}
+
+	if _, err := FlattenDiff(diff); err == nil {
+		t.Error("Expected error for unsupported SQL expression, but got nil")
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	diff := map[string]interface{}{
+		"active":                   false,
+		"whatsAppData.status.mode": "CONNECTED",
+		"data.items.1":             "b",
+		"data.items.-":             []string{"c", "d"},
+		"data.labels.old":          Removed,
+		"data.labels.a/b":          1,
+	}
+
+	operations, err := JSONPatch(diff, nil)
+	if err != nil {
+		t.Fatalf("JSONPatch failed: %v", err)
+	}
+
+	expected := []PatchOperation{
+		{Op: "add", Path: "/active", Value: false},
+		{Op: "add", Path: "/data/items/-", Value: "c"},
+		{Op: "add", Path: "/data/items/-", Value: "d"},
+		{Op: "replace", Path: "/data/items/1", Value: "b"},
+		{Op: "add", Path: "/data/labels/a~1b", Value: 1},
+		{Op: "remove", Path: "/data/labels/old"},
+		{Op: "add", Path: "/whatsAppData/status/mode", Value: "CONNECTED"},
+	}
+	if !reflect.DeepEqual(operations, expected) {
+		t.Errorf("Expected %v, got %v", expected, operations)
+	}
+}
+
+func TestJSONPatch_NumericMapKeys(t *testing.T) {
+	type settings struct {
+		Items  []string          `json:"items"`
+		Labels map[string]string `json:"labels"`
+	}
+	old := &settings{Items: []string{"a", "b"}, Labels: map[string]string{"1": "one"}}
+	updated := &settings{Items: []string{"a", "c"}, Labels: map[string]string{"1": "uno", "2": "two"}}
+
+	diff := make(map[string]interface{})
+	DiffSlice(diff, "items", updated.Items, old.Items)
+	DiffMap(diff, "labels", updated.Labels, old.Labels)
+
+	operations, err := JSONPatch(diff, old)
+	if err != nil {
+		t.Fatalf("JSONPatch failed: %v", err)
+	}
+
+	expected := []PatchOperation{
+		{Op: "replace", Path: "/items/1", Value: "c"},
+		{Op: "add", Path: "/labels/1", Value: "uno"},
+		{Op: "add", Path: "/labels/2", Value: "two"},
+	}
+	if !reflect.DeepEqual(operations, expected) {
+		t.Errorf("Expected new map keys to be added, got %v", operations)
+	}
+}
+
+func TestPatchOperation_MarshalJSON(t *testing.T) {
+	operations := []PatchOperation{
+		{Op: "add", Path: "/active", Value: false},
+		{Op: "add", Path: "/archivedAt", Value: nil},
+		{Op: "remove", Path: "/data/labels/old"},
+	}
+
+	data, err := json.Marshal(operations)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	expected := `[{"op":"add","path":"/active","value":false},{"op":"add","path":"/archivedAt","value":null},{"op":"remove","path":"/data/labels/old"}]`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestGroupDiff(t *testing.T) {
+	fields, nested := GroupDiff(map[string]interface{}{
+		"name":                     "John",
+		"whatsAppData.status.mode": "CONNECTED",
+		"whatsAppData.driverId":    "d1",
+	})
+
+	if !reflect.DeepEqual(fields, map[string]interface{}{"name": "John"}) {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+
+	expected := map[string]map[string]interface{}{
+		"whatsAppData": {"status.mode": "CONNECTED", "driverId": "d1"},
+	}
+	if !reflect.DeepEqual(nested, expected) {
+		t.Errorf("Expected %v, got %v", expected, nested)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	diff := map[string]interface{}{
+		"name":                     "John",
+		"archivedAt":               nil,
+		"whatsAppData.status.mode": "CONNECTED",
+		"whatsAppData.status.qr":   Removed,
+		"data":                     gorm.Expr("? || ?", clause.Column{Name: "data"}, `{"married":true}`),
+	}
+
+	patch, err := MergePatch(diff)
+	if err != nil {
+		t.Fatalf("MergePatch failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":       "John",
+		"archivedAt": nil,
+		"whatsAppData": map[string]interface{}{
+			"status": map[string]interface{}{"mode": "CONNECTED", "qr": nil},
+		},
+		"data": map[string]interface{}{"married": true},
+	}
+	if !reflect.DeepEqual(patch, expected) {
+		t.Errorf("Expected %v, got %v", expected, patch)
+	}
+}
+
+func TestMergePatch_SliceElementPath(t *testing.T) {
+	diff := map[string]interface{}{
+		"data.items.-": []string{"c"},
+	}
+
+	if _, err := MergePatch(diff); err == nil {
+		t.Error("Expected error for slice element path, but got nil")
+	}
+}
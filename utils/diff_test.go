@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+type diffItem struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDiffSlice_NoChanges(t *testing.T) {
+	diff := make(map[string]interface{})
+	DiffSlice(diff, "items", []int{1, 2, 3}, []int{1, 2, 3})
+
+	if len(diff) != 0 {
+		t.Errorf("Expected empty diff, got %v", diff)
+	}
+}
+
+func TestDiffSlice_ModifiedElements(t *testing.T) {
+	diff := make(map[string]interface{})
+	DiffSlice(diff, "items", []int{1, 20, 3, 40}, []int{1, 2, 3, 4})
+
+	expected := map[string]interface{}{
+		"items.1": 20,
+		"items.3": 40,
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected %v, got %v", expected, diff)
+	}
+}
+
+func TestDiffSlice_AppendedElements(t *testing.T) {
+	old := make([]int, 1000)
+	new := append(append([]int{}, old...), 7)
+
+	diff := make(map[string]interface{})
+	DiffSlice(diff, "items", new, old)
+
+	expected := map[string]interface{}{
+		"items.-": []int{7},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected %v, got %v", expected, diff)
+	}
+}
+
+func TestDiffSlice_RemovedElements(t *testing.T) {
+	diff := make(map[string]interface{})
+	DiffSlice(diff, "items", []int{1, 2}, []int{1, 2, 3})
+
+	if !reflect.DeepEqual(diff["items"], []int{1, 2}) {
+		t.Errorf("Expected whole slice for removals, got %v", diff)
+	}
+	if len(diff) != 1 {
+		t.Errorf("Expected only the whole slice in diff, got %v", diff)
+	}
+}
+
+func TestDiffSlice_EmptyOld(t *testing.T) {
+	diff := make(map[string]interface{})
+	DiffSlice(diff, "items", []int{1}, nil)
+
+	if !reflect.DeepEqual(diff, map[string]interface{}{"items": []int{1}}) {
+		t.Errorf("Expected whole slice when old is empty, got %v", diff)
+	}
+}
+
+func TestDiffSliceByKey_ModifiedAndAppended(t *testing.T) {
+	old := []diffItem{{Id: 1, Name: "a"}, {Id: 2, Name: "b"}}
+	new := []diffItem{{Id: 1, Name: "a"}, {Id: 2, Name: "B"}, {Id: 3, Name: "c"}}
+
+	diff := make(map[string]interface{})
+	DiffSliceByKey(diff, "items", new, old, func(item diffItem) int { return item.Id })
+
+	expected := map[string]interface{}{
+		"items.1": diffItem{Id: 2, Name: "B"},
+		"items.-": []diffItem{{Id: 3, Name: "c"}},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected %v, got %v", expected, diff)
+	}
+}
+
+func TestDiffSliceByKey_InsertedInTheMiddle(t *testing.T) {
+	old := []diffItem{{Id: 1}, {Id: 2}}
+	new := []diffItem{{Id: 1}, {Id: 3}, {Id: 2}}
+
+	diff := make(map[string]interface{})
+	DiffSliceByKey(diff, "items", new, old, func(item diffItem) int { return item.Id })
+
+	if !reflect.DeepEqual(diff, map[string]interface{}{"items": new}) {
+		t.Errorf("Expected whole slice when elements shift, got %v", diff)
+	}
+}
+
+func TestDiffMap_ChangedAddedAndRemovedKeys(t *testing.T) {
+	old := map[string]int{"a": 1, "b": 2, "c": 3}
+	new := map[string]int{"a": 1, "b": 20, "d": 4}
+
+	diff := make(map[string]interface{})
+	DiffMap(diff, "labels", new, old)
+
+	expected := map[string]interface{}{
+		"labels.b": 20,
+		"labels.c": Removed,
+		"labels.d": 4,
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected %v, got %v", expected, diff)
+	}
+}
+
+func TestDiffMap_NoChanges(t *testing.T) {
+	diff := make(map[string]interface{})
+	DiffMap(diff, "labels", map[string]string{"a": "x"}, map[string]string{"a": "x"})
+
+	if len(diff) != 0 {
+		t.Errorf("Expected empty diff, got %v", diff)
+	}
+}
+
+func TestDiffMap_WholeMapFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		new  map[string]int
+		old  map[string]int
+	}{
+		{name: "empty old", new: map[string]int{"a": 1}, old: nil},
+		{name: "empty new", new: map[string]int{}, old: map[string]int{"a": 1}},
+		{name: "dotted key", new: map[string]int{"a.b": 2}, old: map[string]int{"a.b": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := make(map[string]interface{})
+			DiffMap(diff, "labels", tt.new, tt.old)
+
+			if !reflect.DeepEqual(diff, map[string]interface{}{"labels": tt.new}) {
+				t.Errorf("Expected whole map, got %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package gormrepository
+
+import (
+	"context"
+	"strings"
+)
+
+// Validatable is implemented by entities that validate themselves before being written
+type Validatable interface {
+	Validate(ctx context.Context) error
+}
+
+// Validator validates entities before they are written, e.g. an adapter
+// around github.com/go-playground/validator
+type Validator interface {
+	Validate(ctx context.Context, entity interface{}) error
+}
+
+// ValidatorFunc adapts a function to the Validator interface
+type ValidatorFunc func(ctx context.Context, entity interface{}) error
+
+// Validate calls f(ctx, entity)
+func (f ValidatorFunc) Validate(ctx context.Context, entity interface{}) error {
+	return f(ctx, entity)
+}
+
+// FieldError describes why a single field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by validators for invalid entities, holding one error per field.
+// Use errors.As to get the field errors out of a repository error.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// WithValidator validates entities with validator before Create, Save and the entity based
+// updates write them. Entities implementing Validatable are validated with or without it.
+func WithValidator(validator Validator) RepositoryOption {
+	return func(config *repositoryConfig) {
+		config.validator = validator
+	}
+}
+
+// validate runs the entity's own validation and then the configured validator
+func (r *GormRepository[T]) validate(ctx context.Context, entity *T) error {
+	if validatable, ok := any(entity).(Validatable); ok {
+		if err := validatable.Validate(ctx); err != nil {
+			return err
+		}
+	}
+
+	if r.config.validator != nil {
+		return r.config.validator.Validate(ctx, entity)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package gormrepository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikateclab/gorm-repository/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func validateProfile(ctx context.Context, entity interface{}) error {
+	profile := entity.(*tests.TestProfile)
+
+	var fields []FieldError
+	if profile.Bio == "" {
+		fields = append(fields, FieldError{Field: "bio", Message: "is required"})
+	}
+	if profile.Website != "" && !strings.HasPrefix(profile.Website, "https://") {
+		fields = append(fields, FieldError{Field: "website", Message: "must use https"})
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func TestGormRepository_Validator_Create(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db, WithValidator(ValidatorFunc(validateProfile)))
+	ctx := context.Background()
+
+	err := repo.Create(ctx, &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Website: "http://insecure.example.com"})
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "Expected a ValidationError, got %v", err)
+	require.Equal(t, []FieldError{
+		{Field: "bio", Message: "is required"},
+		{Field: "website", Message: "must use https"},
+	}, validationErr.Fields)
+	require.Equal(t, "validation failed: bio: is required; website: must use https", err.Error())
+
+	var count int64
+	db.Model(&tests.TestProfile{}).Count(&count)
+	require.Equal(t, int64(0), count, "Expected invalid entity not to be written")
+}
+
+func TestGormRepository_Validator_UpdateInPlace(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormRepository[tests.TestProfile](db, WithValidator(ValidatorFunc(validateProfile)))
+	ctx := context.Background()
+
+	profile := &tests.TestProfile{Id: uuid.New(), UserId: uuid.New(), Bio: "Valid"}
+	require.NoError(t, repo.Create(ctx, profile), "Create should not fail")
+
+	err := repo.UpdateInPlace(ctx, profile, func() {
+		profile.Bio = ""
+	})
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "Expected a ValidationError, got %v", err)
+
+	found, err := repo.FindById(ctx, profile.Id)
+	require.NoError(t, err, "FindById should not fail")
+	require.Equal(t, "Valid", found.Bio, "Expected invalid update not to be written")
+}